@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kgugunava/effective_mobile_golang/internal/domain"
+	"github.com/kgugunava/effective_mobile_golang/internal/service"
+)
+
+const apiKeyUsage = `usage:
+  main apikey issue -name NAME -scopes read,write,admin [-expires-in DURATION]
+  main apikey revoke KEY_ID`
+
+// runAPIKeyCommand issues or revokes API keys as requested by args, the
+// command line after "apikey". The key of an issued API key is written to
+// out; it is not stored and is shown only this once.
+func runAPIKeyCommand(ctx context.Context, keys *service.APIKeyService, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(apiKeyUsage)
+	}
+
+	switch args[0] {
+	case "issue":
+		flags := flag.NewFlagSet("apikey issue", flag.ContinueOnError)
+		name := flags.String("name", "", "name of the service the key is issued to")
+		scopesFlag := flags.String("scopes", "", "comma-separated scopes: read, write, admin")
+		expiresIn := flags.Duration("expires-in", 0, "lifetime of the key, it does not expire when unset")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		var scopes []domain.Scope
+		for _, scope := range strings.Split(*scopesFlag, ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				scopes = append(scopes, domain.Scope(scope))
+			}
+		}
+		var expiresAt *time.Time
+		if *expiresIn > 0 {
+			at := time.Now().Add(*expiresIn)
+			expiresAt = &at
+		}
+
+		key, secret, err := keys.IssueAPIKey(ctx, *name, scopes, expiresAt)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "key_id: %s\n", key.KeyID)
+		if key.ExpiresAt != nil {
+			fmt.Fprintf(out, "expires_at: %s\n", key.ExpiresAt.Format(time.RFC3339))
+		}
+		fmt.Fprintf(out, "api_key: %s\n", secret)
+		fmt.Fprintln(out, "Store the API key now, it cannot be shown again.")
+		return nil
+
+	case "revoke":
+		if len(args) != 2 {
+			return errors.New(apiKeyUsage)
+		}
+		id, err := uuid.Parse(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid key ID %q: %w", args[1], err)
+		}
+		if err := keys.RevokeAPIKey(ctx, id); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "revoked: %s\n", id)
+		return nil
+	}
+	return errors.New(apiKeyUsage)
+}
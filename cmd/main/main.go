@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"log"
 	"log/slog"
 	"os"
@@ -12,31 +11,39 @@ import (
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/kgugunava/effective_mobile_golang/internal/adapters/postgres"
 	"github.com/kgugunava/effective_mobile_golang/internal/app"
 	"github.com/kgugunava/effective_mobile_golang/internal/config"
+	"github.com/kgugunava/effective_mobile_golang/internal/service"
 )
 
 func main() {
 	logger := initLogger()
 	cfg := config.NewConfig()
-	cfg.InitConfig()
-
-	dbURL := fmt.Sprintf("postgresql://%s:%s@%s:%s/%s?sslmode=%s",
-		cfg.DbUser,
-		cfg.DbPassword,
-		cfg.DbHost,
-		cfg.DbPort,
-		cfg.DbName,
-		cfg.SslMode,
-	)
 
-	runMigrations(logger, dbURL)
+	// The apikey command only needs the database. It does not require the
+	// server's JWT settings and leaves migrations to the server.
+	if len(os.Args) > 1 && os.Args[1] == "apikey" {
+		cfg.InitDBConfig()
+		db := connectDB(logger, cfg.DatabaseURL())
+		apiKeys := service.NewAPIKeyService(postgres.NewAPIKeyRepository(db, logger), logger)
+		if err := runAPIKeyCommand(context.Background(), apiKeys, os.Args[2:], os.Stdout); err != nil {
+			db.Close()
+			log.Fatal("error in apikey command: ", err)
+		}
+		db.Close()
+		return
+	}
 
-	db, err := pgxpool.New(context.Background(), dbURL)
-	if err != nil {
-		logger.Error("failed to connect to database", slog.Any("error", err))
-		log.Fatal("error in connecting to DB: ", err)
+	if err := cfg.InitConfig(); err != nil {
+		logger.Error("failed to load config", slog.Any("error", err))
+		log.Fatal("error in config: ", err)
 	}
+
+	dbURL := cfg.DatabaseURL()
+	runMigrations(logger, dbURL)
+
+	db := connectDB(logger, dbURL)
 	defer db.Close()
 
 	application := app.NewApp(db, logger, cfg)
@@ -44,6 +51,15 @@ func main() {
 	application.Router.Run(cfg.ServerAddress)
 }
 
+func connectDB(logger *slog.Logger, dbURL string) *pgxpool.Pool {
+	db, err := pgxpool.New(context.Background(), dbURL)
+	if err != nil {
+		logger.Error("failed to connect to database", slog.Any("error", err))
+		log.Fatal("error in connecting to DB: ", err)
+	}
+	return db
+}
+
 func runMigrations(logger *slog.Logger, dbURL string) {
 	logger.Info("starting database migrations", slog.String("db_url", dbURL))
 
@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type APIKeyRepository struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+func NewAPIKeyRepository(pool *pgxpool.Pool, logger *slog.Logger) *APIKeyRepository {
+	return &APIKeyRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+func (r *APIKeyRepository) Create(ctx context.Context, key APIKeyEntity) (APIKeyEntity, error) {
+	query := `
+		INSERT INTO api_keys (key_id, name, key_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING key_id, name, key_hash, scopes, expires_at, created_at, revoked_at
+	`
+
+	var created APIKeyEntity
+	rows, err := r.pool.Query(ctx, query, key.KeyID, key.Name, key.KeyHash, key.Scopes, key.ExpiresAt)
+	if err == nil {
+		created, err = pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[APIKeyEntity])
+	}
+	if err != nil {
+		r.logger.Error("failed to insert API key into DB",
+			slog.String("key_id", key.KeyID.String()),
+			slog.Any("error", err),
+		)
+		return APIKeyEntity{}, fmt.Errorf("failed to insert API key: %w", classifyError(err))
+	}
+	return created, nil
+}
+
+// GetByHash returns the API key with the given hash, including revoked and
+// expired keys.
+func (r *APIKeyRepository) GetByHash(ctx context.Context, keyHash string) (APIKeyEntity, error) {
+	query := `
+		SELECT key_id, name, key_hash, scopes, expires_at, created_at, revoked_at
+		FROM api_keys
+		WHERE key_hash = $1
+	`
+
+	rows, err := r.pool.Query(ctx, query, keyHash)
+	if err != nil {
+		return APIKeyEntity{}, fmt.Errorf("failed to fetch API key: %w", err)
+	}
+	key, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[APIKeyEntity])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return APIKeyEntity{}, fmt.Errorf("API key: %w", ErrNotFound)
+		}
+		r.logger.Error("failed to scan API key row",
+			slog.Any("error", err),
+		)
+		return APIKeyEntity{}, fmt.Errorf("failed to scan API key: %w", err)
+	}
+	return key, nil
+}
+
+// Revoke marks the API key as revoked. It returns ErrNotFound when there is
+// no such key or it is already revoked.
+func (r *APIKeyRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE api_keys
+		SET revoked_at = now()
+		WHERE key_id = $1 AND revoked_at IS NULL
+	`
+
+	tag, err := r.pool.Exec(ctx, query, id)
+	if err != nil {
+		r.logger.Error("failed to revoke API key",
+			slog.String("key_id", id.String()),
+			slog.Any("error", err),
+		)
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("API key %s: %w", id, ErrNotFound)
+	}
+	return nil
+}
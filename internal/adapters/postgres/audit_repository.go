@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AuditRepository struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+func NewAuditRepository(pool *pgxpool.Pool, logger *slog.Logger) *AuditRepository {
+	return &AuditRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+func (r *AuditRepository) Record(ctx context.Context, entries []AuditEntity) error {
+	query := `
+		INSERT INTO subscription_audit (subscription_id, user_id, action, actor, request_id, before, after)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	batch := &pgx.Batch{}
+	for _, entry := range entries {
+		batch.Queue(query, entry.SubscriptionID, entry.UserID, entry.Action, entry.Actor, entry.RequestID, entry.Before, entry.After)
+	}
+
+	if err := conn(ctx, r.pool).SendBatch(ctx, batch).Close(); err != nil {
+		r.logger.Error("failed to record audit entries",
+			slog.Int("count", len(entries)),
+			slog.Any("error", err),
+		)
+		return fmt.Errorf("failed to record audit entries: %w", err)
+	}
+	return nil
+}
+
+func (r *AuditRepository) List(ctx context.Context, filter AuditFilter) ([]AuditEntity, error) {
+	query := `
+		SELECT audit_id, subscription_id, user_id, action, actor, request_id, before, after, created_at
+		FROM subscription_audit
+		WHERE ($1::uuid IS NULL OR subscription_id = $1)
+			AND ($2::uuid IS NULL OR user_id = $2)
+			AND ($3::bigint IS NULL OR audit_id < $3)
+		ORDER BY audit_id DESC
+		LIMIT $4`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, filter.SubscriptionID, filter.UserID, filter.Before, filter.Limit)
+	if err != nil {
+		r.logger.Error("failed to execute audit query",
+			slog.Any("error", err),
+		)
+		return nil, fmt.Errorf("failed to fetch audit entries: %w", err)
+	}
+
+	entries, err := pgx.CollectRows(rows, pgx.RowToStructByName[AuditEntity])
+	if err != nil {
+		r.logger.Error("failed to scan audit rows",
+			slog.Any("error", err),
+		)
+		return nil, fmt.Errorf("failed to scan audit entries: %w", err)
+	}
+
+	return entries, nil
+}
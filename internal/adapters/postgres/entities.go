@@ -9,8 +9,72 @@ import (
 type SubscriptionEntity struct {
 	SubscriptionID uuid.UUID `db:"subscription_id"`
 	ServiceName string `db:"service_name"`
+	// Price is the initial price the price changes apply on top of,
+	// CurrentPrice the one in effect in the current month.
 	Price int `db:"price"`
+	CurrentPrice int `db:"current_price"`
 	UserID uuid.UUID `db:"user_id"`
 	StartDate time.Time `db:"start_date"`
 	EndDate *time.Time `db:"end_date"`
-}
\ No newline at end of file
+	BillingPeriod string `db:"billing_period"`
+	Currency string `db:"currency"`
+	Status string `db:"status"`
+	Version int `db:"version"`
+	UpdatedAt time.Time `db:"updated_at"`
+	DeletedAt *time.Time `db:"deleted_at"`
+}
+
+type SubscriptionPauseEntity struct {
+	SubscriptionID uuid.UUID `db:"subscription_id"`
+	StartMonth time.Time `db:"start_month"`
+	EndMonth time.Time `db:"end_month"`
+}
+
+type IdempotencyKeyEntity struct {
+	UserID uuid.UUID `db:"user_id"`
+	Key string `db:"idempotency_key"`
+	RequestHash string `db:"request_hash"`
+	ExpiresAt time.Time `db:"expires_at"`
+}
+
+type MonthlyServiceSpendEntity struct {
+	Month time.Time `db:"month"`
+	ServiceName string `db:"service_name"`
+	Total int64 `db:"total"`
+}
+
+
+type ExchangeRateEntity struct {
+	Currency string `db:"currency"`
+	Rate float64 `db:"rate"`
+	EffectiveFrom time.Time `db:"effective_from"`
+}
+
+type SubscriptionPriceEntity struct {
+	SubscriptionID uuid.UUID `db:"subscription_id"`
+	Price int `db:"price"`
+	EffectiveFrom time.Time `db:"effective_from"`
+}
+
+
+type AuditEntity struct {
+	AuditID int64 `db:"audit_id"`
+	SubscriptionID uuid.UUID `db:"subscription_id"`
+	UserID uuid.UUID `db:"user_id"`
+	Action string `db:"action"`
+	Actor string `db:"actor"`
+	RequestID string `db:"request_id"`
+	Before []byte `db:"before"`
+	After []byte `db:"after"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type APIKeyEntity struct {
+	KeyID uuid.UUID `db:"key_id"`
+	Name string `db:"name"`
+	KeyHash string `db:"key_hash"`
+	Scopes []string `db:"scopes"`
+	ExpiresAt *time.Time `db:"expires_at"`
+	CreatedAt time.Time `db:"created_at"`
+	RevokedAt *time.Time `db:"revoked_at"`
+}
@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrNotFound             = errors.New("record not found")
+	ErrDuplicate            = errors.New("duplicate record")
+	ErrConstraint           = errors.New("constraint violation")
+	ErrMissingExchangeRate  = errors.New("missing exchange rate")
+	ErrVersionMismatch      = errors.New("version mismatch")
+	ErrIdempotencyKeyReused = errors.New("idempotency key reused with a different request")
+)
+
+// BatchItemError reports the entity of a batch that the database rejected.
+type BatchItemError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchItemError) Error() string {
+	return fmt.Sprintf("batch item %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchItemError) Unwrap() error {
+	return e.Err
+}
+
+// classifyError tags PostgreSQL integrity violations with ErrDuplicate or
+// ErrConstraint so callers can tell them apart from infrastructure failures.
+func classifyError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	switch pgErr.Code {
+	case "23505":
+		return errors.Join(ErrDuplicate, err)
+	case "23502", "23503", "23514":
+		return errors.Join(ErrConstraint, err)
+	}
+	return err
+}
@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ExchangeRateRepository struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+func NewExchangeRateRepository(pool *pgxpool.Pool, logger *slog.Logger) *ExchangeRateRepository {
+	return &ExchangeRateRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+func (r *ExchangeRateRepository) UpsertRates(ctx context.Context, rates []ExchangeRateEntity) error {
+	query := `
+		INSERT INTO exchange_rates (currency, rate, effective_from)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (currency, effective_from) DO UPDATE SET rate = EXCLUDED.rate
+	`
+
+	batch := &pgx.Batch{}
+	for _, rate := range rates {
+		batch.Queue(query, rate.Currency, rate.Rate, rate.EffectiveFrom)
+	}
+
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		return tx.SendBatch(ctx, batch).Close()
+	})
+	if err != nil {
+		r.logger.Error("failed to upsert exchange rates",
+			slog.Int("count", len(rates)),
+			slog.Any("error", err),
+		)
+		return fmt.Errorf("failed to upsert exchange rates: %w", err)
+	}
+
+	r.logger.Info("exchange rates upserted",
+		slog.Int("count", len(rates)),
+	)
+	return nil
+}
@@ -0,0 +1,52 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CostFilter struct {
+	UserID uuid.UUID
+	ServiceName string
+	StartDate time.Time
+	EndDate time.Time
+	Basis string
+	Currency string
+}
+
+
+type SubscriptionListFilter struct {
+	ServiceName string
+	ServiceNameMatch string
+	UserID uuid.UUID
+	StartDate time.Time
+	EndDate time.Time
+	PeriodMode string
+	ActiveAt *time.Time
+	MinPrice *int
+	MaxPrice *int
+	HasEndDate *bool
+	Deleted bool
+	SortBy string
+	Order string
+	Limit int
+	After *ListCursor
+}
+
+// ListCursor is the keyset position of the last row of a page: the text
+// form of its sort key and its subscription ID as a tie-breaker.
+type ListCursor struct {
+	SortValue string
+	SubscriptionID uuid.UUID
+}
+
+
+// AuditFilter selects the audit entries of one subscription or of one user,
+// newest first. Before is the audit ID to continue after, nil for the start.
+type AuditFilter struct {
+	SubscriptionID *uuid.UUID
+	UserID *uuid.UUID
+	Before *int64
+	Limit int
+}
\ No newline at end of file
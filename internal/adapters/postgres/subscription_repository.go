@@ -2,17 +2,48 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// currentPriceExpr is the price a subscription is charged in the current
+// month, or in its first month when it has not started yet: the latest price
+// change in effect by then, or else its initial price.
+const currentPriceExpr = `COALESCE((
+		SELECT sp.price
+		FROM subscription_prices sp
+		WHERE sp.subscription_id = subscriptions.subscription_id
+			AND sp.effective_from <= GREATEST(date_trunc('month', now()), date_trunc('month', subscriptions.start_date))
+		ORDER BY sp.effective_from DESC
+		LIMIT 1
+	), subscriptions.price)`
+
+// statusExpr is the status of a subscription. Only a cancellation is
+// stored; a subscription is paused while the current month falls in one of
+// its pauses, so that a pause takes effect and ends on its own.
+const statusExpr = `CASE
+		WHEN subscriptions.status = 'cancelled' THEN 'cancelled'
+		WHEN EXISTS (
+			SELECT 1
+			FROM subscription_pauses pause
+			WHERE pause.subscription_id = subscriptions.subscription_id
+				AND date_trunc('month', now())::date BETWEEN pause.start_month AND pause.end_month
+		) THEN 'paused'
+		ELSE 'active'
+	END`
+
+const subscriptionColumns = `subscription_id, service_name, price, ` + currentPriceExpr + `, user_id, start_date, end_date, billing_period, currency, ` + statusExpr + `, version, updated_at, deleted_at`
+
 type SubscriptionRepository struct {
 	pool   *pgxpool.Pool
 	logger *slog.Logger
@@ -25,56 +56,196 @@ func NewSubscriptionRepository(pool *pgxpool.Pool, logger *slog.Logger) *Subscri
 	}
 }
 
-func (r *SubscriptionRepository) Create(ctx context.Context, subscription SubscriptionEntity) error {
-	query := `
-		INSERT INTO subscriptions (subscription_id, user_id, service_name, price, start_date, end_date)
-		VALUES ($1, $2, $3, $4, $5, $6)
-	`
-
-	_, err := r.pool.Exec(ctx, query,
-		subscription.SubscriptionID,
-		subscription.UserID,
-		subscription.ServiceName,
-		subscription.Price,
-		subscription.StartDate,
-		subscription.EndDate,
-	)
+func (r *SubscriptionRepository) Create(ctx context.Context, subscription SubscriptionEntity) (SubscriptionEntity, error) {
+	created, err := insertSubscription(ctx, conn(ctx, r.pool), subscription)
 	if err != nil {
 		r.logger.Error("failed to insert subscription into DB",
 			slog.String("subscription_id", subscription.SubscriptionID.String()),
 			slog.Any("error", err),
 		)
-		return fmt.Errorf("failed to insert subscription: %w", err)
+		return SubscriptionEntity{}, fmt.Errorf("failed to insert subscription: %w", classifyError(err))
 	}
 
 	r.logger.Info("subscription created successfully",
 		slog.String("subscription_id", subscription.SubscriptionID.String()),
 	)
-	return nil
+	return created, nil
+}
+
+// CreateIdempotent inserts the subscription and records it under the
+// idempotency key of its user in one transaction. If the user already uses
+// the key and it has not expired, nothing is inserted and the subscription
+// stored with the key is returned with replayed set.
+func (r *SubscriptionRepository) CreateIdempotent(ctx context.Context, subscription SubscriptionEntity, key IdempotencyKeyEntity) (SubscriptionEntity, bool, error) {
+	var (
+		result   SubscriptionEntity
+		replayed bool
+	)
+
+	err := pgx.BeginFunc(ctx, conn(ctx, r.pool), func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM idempotency_keys WHERE user_id = $1 AND idempotency_key = $2 AND expires_at <= now()`, key.UserID, key.Key); err != nil {
+			return fmt.Errorf("failed to remove expired idempotency key: %w", err)
+		}
+
+		// Concurrent requests with the same key block here until the first
+		// one commits and then fall through to the replay branch.
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO idempotency_keys (user_id, idempotency_key, request_hash, expires_at)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (user_id, idempotency_key) DO NOTHING
+		`, key.UserID, key.Key, key.RequestHash, key.ExpiresAt)
+		if err != nil {
+			return fmt.Errorf("failed to reserve idempotency key: %w", err)
+		}
+
+		if tag.RowsAffected() == 0 {
+			var (
+				requestHash string
+				response    []byte
+			)
+			err := tx.QueryRow(ctx, `SELECT request_hash, response FROM idempotency_keys WHERE user_id = $1 AND idempotency_key = $2`, key.UserID, key.Key).
+				Scan(&requestHash, &response)
+			if err != nil {
+				return fmt.Errorf("failed to read idempotency key: %w", err)
+			}
+			if requestHash != key.RequestHash {
+				return ErrIdempotencyKeyReused
+			}
+			if err := json.Unmarshal(response, &result); err != nil {
+				return fmt.Errorf("failed to decode stored response: %w", err)
+			}
+			replayed = true
+			return nil
+		}
+
+		created, err := insertSubscription(ctx, tx, subscription)
+		if err != nil {
+			return fmt.Errorf("failed to insert subscription: %w", classifyError(err))
+		}
+
+		response, err := json.Marshal(created)
+		if err != nil {
+			return fmt.Errorf("failed to encode response: %w", err)
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE idempotency_keys SET subscription_id = $3, response = $4
+			WHERE user_id = $1 AND idempotency_key = $2
+		`, key.UserID, key.Key, created.SubscriptionID, response)
+		if err != nil {
+			return fmt.Errorf("failed to store idempotent response: %w", err)
+		}
+
+		result = created
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("failed to create subscription with idempotency key",
+			slog.String("subscription_id", subscription.SubscriptionID.String()),
+			slog.Any("error", err),
+		)
+		return SubscriptionEntity{}, false, err
+	}
+
+	if replayed {
+		r.logger.Info("idempotent create replayed",
+			slog.String("subscription_id", result.SubscriptionID.String()),
+		)
+	} else {
+		r.logger.Info("subscription created successfully",
+			slog.String("subscription_id", result.SubscriptionID.String()),
+		)
+	}
+	return result, replayed, nil
+}
+
+// CreateBatch inserts all subscriptions in one transaction using a single
+// batch round trip. If the database rejects one of them, nothing is inserted
+// and a *BatchItemError names the offending entity.
+func (r *SubscriptionRepository) CreateBatch(ctx context.Context, subscriptions []SubscriptionEntity) ([]SubscriptionEntity, error) {
+	batch := &pgx.Batch{}
+	for _, subscription := range subscriptions {
+		batch.Queue(insertSubscriptionQuery, insertSubscriptionArgs(subscription)...)
+	}
+
+	created := make([]SubscriptionEntity, len(subscriptions))
+	err := pgx.BeginFunc(ctx, conn(ctx, r.pool), func(tx pgx.Tx) error {
+		results := tx.SendBatch(ctx, batch)
+		defer results.Close()
+
+		for i := range subscriptions {
+			entity, err := scanSubscription(results.QueryRow())
+			if err != nil {
+				var pgErr *pgconn.PgError
+				if errors.As(err, &pgErr) {
+					return &BatchItemError{Index: i, Err: classifyError(err)}
+				}
+				return err
+			}
+			created[i] = entity
+		}
+		return results.Close()
+	})
+	if err != nil {
+		r.logger.Error("failed to insert subscription batch",
+			slog.Int("count", len(subscriptions)),
+			slog.Any("error", err),
+		)
+		return nil, fmt.Errorf("failed to insert subscriptions: %w", err)
+	}
+
+	r.logger.Info("subscription batch created",
+		slog.Int("count", len(created)),
+	)
+	return created, nil
+}
+
+const insertSubscriptionQuery = `
+	INSERT INTO subscriptions (subscription_id, user_id, service_name, price, start_date, end_date, billing_period, currency)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	RETURNING ` + subscriptionColumns
+
+func insertSubscriptionArgs(subscription SubscriptionEntity) []any {
+	return []any{
+		subscription.SubscriptionID,
+		subscription.UserID,
+		subscription.ServiceName,
+		subscription.Price,
+		subscription.StartDate,
+		subscription.EndDate,
+		subscription.BillingPeriod,
+		subscription.Currency,
+	}
+}
+
+func insertSubscription(ctx context.Context, q rowQuerier, subscription SubscriptionEntity) (SubscriptionEntity, error) {
+	return scanSubscription(q.QueryRow(ctx, insertSubscriptionQuery, insertSubscriptionArgs(subscription)...))
 }
 
 func (r *SubscriptionRepository) GetByID(ctx context.Context, id uuid.UUID) (SubscriptionEntity, error) {
+	return r.getByID(ctx, id, "")
+}
+
+// GetByIDForUpdate is GetByID that also locks the row until the end of the
+// transaction ctx carries, so that the subscription cannot change between
+// reading it and the caller's update.
+func (r *SubscriptionRepository) GetByIDForUpdate(ctx context.Context, id uuid.UUID) (SubscriptionEntity, error) {
+	return r.getByID(ctx, id, "FOR UPDATE")
+}
+
+func (r *SubscriptionRepository) getByID(ctx context.Context, id uuid.UUID, lock string) (SubscriptionEntity, error) {
 	query := `
-		SELECT subscription_id, service_name, price, user_id, start_date, end_date
+		SELECT ` + subscriptionColumns + `
 		FROM subscriptions
-		WHERE subscription_id = $1
-	`
+		WHERE subscription_id = $1 AND deleted_at IS NULL
+		` + lock
 
-	var entity SubscriptionEntity
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&entity.SubscriptionID,
-		&entity.ServiceName,
-		&entity.Price,
-		&entity.UserID,
-		&entity.StartDate,
-		&entity.EndDate,
-	)
+	entity, err := scanSubscription(conn(ctx, r.pool).QueryRow(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			r.logger.Warn("subscription not found",
 				slog.String("subscription_id", id.String()),
 			)
-			return SubscriptionEntity{}, fmt.Errorf("subscription not found")
+			return SubscriptionEntity{}, fmt.Errorf("subscription %s: %w", id, ErrNotFound)
 		}
 		r.logger.Error("failed to get subscription",
 			slog.String("subscription_id", id.String()),
@@ -89,41 +260,63 @@ func (r *SubscriptionRepository) GetByID(ctx context.Context, id uuid.UUID) (Sub
 	return entity, nil
 }
 
-func (r *SubscriptionRepository) UpdatePut(ctx context.Context, sub SubscriptionEntity, id uuid.UUID) (SubscriptionEntity, error) {
+// GetOwner returns the ID of the user a subscription belongs to, including
+// subscriptions in the trash.
+func (r *SubscriptionRepository) GetOwner(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
 	query := `
-		UPDATE subscriptions
-		SET service_name = $2, price = $3, user_id = $4, start_date = $5, end_date = $6
+		SELECT user_id
+		FROM subscriptions
 		WHERE subscription_id = $1
-		RETURNING subscription_id, service_name, price, user_id, start_date, end_date`
+	`
 
-	var updated SubscriptionEntity
-	err := r.pool.QueryRow(ctx, query,
+	var userID uuid.UUID
+	if err := conn(ctx, r.pool).QueryRow(ctx, query, id).Scan(&userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, fmt.Errorf("subscription %s: %w", id, ErrNotFound)
+		}
+		r.logger.Error("failed to get subscription owner",
+			slog.String("subscription_id", id.String()),
+			slog.Any("error", err),
+		)
+		return uuid.Nil, fmt.Errorf("failed to get subscription owner: %w", err)
+	}
+	return userID, nil
+}
+
+// UpdatePut replaces a subscription except for its price, which is changed
+// with UpsertPrice so that the months already charged keep their price. When
+// expectedVersions is not nil the update only happens if the stored version
+// is one of them.
+func (r *SubscriptionRepository) UpdatePut(ctx context.Context, sub SubscriptionEntity, id uuid.UUID, expectedVersions []int) (SubscriptionEntity, error) {
+	query := `
+		UPDATE subscriptions
+		SET service_name = $2, user_id = $3, start_date = $4, end_date = $5, billing_period = $6, currency = $7,
+			version = version + 1, updated_at = now()
+		WHERE subscription_id = $1 AND deleted_at IS NULL AND ($8::integer[] IS NULL OR version = ANY($8))
+		RETURNING ` + subscriptionColumns
+
+	updated, err := scanSubscription(conn(ctx, r.pool).QueryRow(ctx, query,
 		id,
 		sub.ServiceName,
-		sub.Price,
 		sub.UserID,
 		sub.StartDate,
 		sub.EndDate,
-	).Scan(
-		&updated.SubscriptionID,
-		&updated.ServiceName,
-		&updated.Price,
-		&updated.UserID,
-		&updated.StartDate,
-		&updated.EndDate,
-	)
+		sub.BillingPeriod,
+		sub.Currency,
+		expectedVersions,
+	))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			r.logger.Warn("subscription not found for update",
 				slog.String("subscription_id", id.String()),
 			)
-			return SubscriptionEntity{}, fmt.Errorf("subscription not found")
+			return SubscriptionEntity{}, r.missingRowError(ctx, id)
 		}
 		r.logger.Error("update failed",
 			slog.String("subscription_id", id.String()),
 			slog.Any("error", err),
 		)
-		return SubscriptionEntity{}, fmt.Errorf("update failed: %w", err)
+		return SubscriptionEntity{}, fmt.Errorf("update failed: %w", classifyError(err))
 	}
 
 	r.logger.Info("subscription updated (PUT)",
@@ -132,18 +325,18 @@ func (r *SubscriptionRepository) UpdatePut(ctx context.Context, sub Subscription
 	return updated, nil
 }
 
-func (r *SubscriptionRepository) UpdatePatch(ctx context.Context, id uuid.UUID, changes map[string]interface{}) (SubscriptionEntity, error) {
-	if len(changes) == 0 {
-		r.logger.Debug("no fields to update, returning current state",
-			slog.String("subscription_id", id.String()),
-		)
-		return r.GetByID(ctx, id)
-	}
-
+// UpdatePatch updates the given columns of a subscription and bumps its
+// version, also when changes is empty. The price is changed with UpsertPrice
+// instead. When expectedVersions is not nil the update only happens if the
+// stored version is one of them.
+func (r *SubscriptionRepository) UpdatePatch(ctx context.Context, id uuid.UUID, changes map[string]interface{}, expectedVersions []int) (SubscriptionEntity, error) {
 	allowedFields := map[string]bool{
-		"service_name": true,
-		"price":        true,
-		"end_date":     true,
+		"service_name":   true,
+		"user_id":        true,
+		"start_date":     true,
+		"end_date":       true,
+		"billing_period": true,
+		"currency":       true,
 	}
 
 	var setClauses []string
@@ -164,38 +357,35 @@ func (r *SubscriptionRepository) UpdatePatch(ctx context.Context, id uuid.UUID,
 		argIndex++
 	}
 
+	setClauses = append(setClauses, "version = version + 1", "updated_at = now()")
+
 	query := fmt.Sprintf(`
 		UPDATE subscriptions 
 		SET %s 
-		WHERE subscription_id = $1 
-		RETURNING subscription_id, user_id, service_name, price, start_date, end_date`,
+		WHERE subscription_id = $1 AND deleted_at IS NULL AND ($%d::integer[] IS NULL OR version = ANY($%d))
+		RETURNING %s`,
 		strings.Join(setClauses, ", "),
+		argIndex, argIndex,
+		subscriptionColumns,
 	)
 
 	args = append([]interface{}{id}, args...)
+	args = append(args, expectedVersions)
 
-	var updated SubscriptionEntity
-	err := r.pool.QueryRow(ctx, query, args...).Scan(
-		&updated.SubscriptionID,
-		&updated.UserID,
-		&updated.ServiceName,
-		&updated.Price,
-		&updated.StartDate,
-		&updated.EndDate,
-	)
+	updated, err := scanSubscription(conn(ctx, r.pool).QueryRow(ctx, query, args...))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			r.logger.Warn("subscription not found for patch",
 				slog.String("subscription_id", id.String()),
 			)
-			return SubscriptionEntity{}, fmt.Errorf("subscription not found")
+			return SubscriptionEntity{}, r.missingRowError(ctx, id)
 		}
 		r.logger.Error("failed to patch subscription",
 			slog.String("subscription_id", id.String()),
 			slog.Any("error", err),
 			slog.Any("changes", changes),
 		)
-		return SubscriptionEntity{}, fmt.Errorf("failed to patch subscription: %w", err)
+		return SubscriptionEntity{}, fmt.Errorf("failed to patch subscription: %w", classifyError(err))
 	}
 
 	r.logger.Info("subscription patched",
@@ -205,10 +395,17 @@ func (r *SubscriptionRepository) UpdatePatch(ctx context.Context, id uuid.UUID,
 	return updated, nil
 }
 
-func (r *SubscriptionRepository) DeleteByID(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM subscriptions WHERE subscription_id = $1`
+// DeleteByID moves a subscription to the trash by setting its deleted_at.
+// It is removed for good by PurgeDeleted once the retention period is over.
+// When expectedVersions is not nil the delete only happens if the stored
+// version is one of them.
+func (r *SubscriptionRepository) DeleteByID(ctx context.Context, id uuid.UUID, expectedVersions []int) error {
+	query := `
+		UPDATE subscriptions
+		SET deleted_at = now(), version = version + 1, updated_at = now()
+		WHERE subscription_id = $1 AND deleted_at IS NULL AND ($2::integer[] IS NULL OR version = ANY($2))`
 
-	result, err := r.pool.Exec(ctx, query, id)
+	result, err := conn(ctx, r.pool).Exec(ctx, query, id, expectedVersions)
 	if err != nil {
 		r.logger.Error("failed to delete subscription",
 			slog.String("subscription_id", id.String()),
@@ -221,7 +418,7 @@ func (r *SubscriptionRepository) DeleteByID(ctx context.Context, id uuid.UUID) e
 		r.logger.Warn("delete requested but subscription not found",
 			slog.String("subscription_id", id.String()),
 		)
-		return fmt.Errorf("subscription not found")
+		return r.missingRowError(ctx, id)
 	}
 
 	r.logger.Info("subscription deleted",
@@ -230,63 +427,194 @@ func (r *SubscriptionRepository) DeleteByID(ctx context.Context, id uuid.UUID) e
 	return nil
 }
 
-func (r *SubscriptionRepository) GetSubscriptionsList(ctx context.Context, serviceName string, userID uuid.UUID, startDate time.Time, endDate time.Time) ([]SubscriptionEntity, error) {
+// Restore takes a subscription out of the trash.
+func (r *SubscriptionRepository) Restore(ctx context.Context, id uuid.UUID) (SubscriptionEntity, error) {
 	query := `
-		SELECT subscription_id, service_name, price, user_id, start_date, end_date
-		FROM subscriptions
-		WHERE 1=1`
+		UPDATE subscriptions
+		SET deleted_at = NULL, version = version + 1, updated_at = now()
+		WHERE subscription_id = $1 AND deleted_at IS NOT NULL
+		RETURNING ` + subscriptionColumns
 
-	var args []interface{}
-	argPos := 1
+	restored, err := scanSubscription(conn(ctx, r.pool).QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Warn("deleted subscription not found for restore",
+				slog.String("subscription_id", id.String()),
+			)
+			return SubscriptionEntity{}, fmt.Errorf("deleted subscription %s: %w", id, ErrNotFound)
+		}
+		r.logger.Error("failed to restore subscription",
+			slog.String("subscription_id", id.String()),
+			slog.Any("error", err),
+		)
+		return SubscriptionEntity{}, fmt.Errorf("failed to restore subscription: %w", err)
+	}
 
-	query += fmt.Sprintf(" AND user_id = $%d", argPos)
-	args = append(args, userID)
-	argPos++
+	r.logger.Info("subscription restored",
+		slog.String("subscription_id", id.String()),
+	)
+	return restored, nil
+}
 
-	if serviceName != "" {
-		query += fmt.Sprintf(" AND service_name = $%d", argPos)
-		args = append(args, serviceName)
-		argPos++
+// Cancel marks a subscription as cancelled and sets its end date.
+func (r *SubscriptionRepository) Cancel(ctx context.Context, id uuid.UUID, endDate time.Time, expectedVersions []int) (SubscriptionEntity, error) {
+	return r.changeStatus(ctx, id, expectedVersions, func(tx pgx.Tx) (SubscriptionEntity, error) {
+		return updateStatus(ctx, tx, id, "cancelled", &endDate, expectedVersions)
+	})
+}
+
+// Pause records the months a subscription is paused for. It is paused while
+// the current month is one of them, see statusExpr.
+func (r *SubscriptionRepository) Pause(ctx context.Context, pause SubscriptionPauseEntity, expectedVersions []int) (SubscriptionEntity, error) {
+	return r.changeStatus(ctx, pause.SubscriptionID, expectedVersions, func(tx pgx.Tx) (SubscriptionEntity, error) {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO subscription_pauses (subscription_id, start_month, end_month)
+			VALUES ($1, $2, $3)
+		`, pause.SubscriptionID, pause.StartMonth, pause.EndMonth)
+		if err != nil {
+			return SubscriptionEntity{}, fmt.Errorf("failed to record pause: %w", classifyError(err))
+		}
+		// The pause is recorded first so that the returned status reflects it.
+		return updateStatus(ctx, tx, pause.SubscriptionID, "active", nil, expectedVersions)
+	})
+}
+
+// Resume makes a paused subscription active again from resumeMonth on.
+// Pauses are cut short so that they end before resumeMonth.
+func (r *SubscriptionRepository) Resume(ctx context.Context, id uuid.UUID, resumeMonth time.Time, expectedVersions []int) (SubscriptionEntity, error) {
+	return r.changeStatus(ctx, id, expectedVersions, func(tx pgx.Tx) (SubscriptionEntity, error) {
+		if _, err := tx.Exec(ctx, `DELETE FROM subscription_pauses WHERE subscription_id = $1 AND start_month >= $2`, id, resumeMonth); err != nil {
+			return SubscriptionEntity{}, fmt.Errorf("failed to remove pending pauses: %w", err)
+		}
+		_, err := tx.Exec(ctx, `
+			UPDATE subscription_pauses SET end_month = ($2::date - interval '1 month')::date
+			WHERE subscription_id = $1 AND end_month >= $2
+		`, id, resumeMonth)
+		if err != nil {
+			return SubscriptionEntity{}, fmt.Errorf("failed to end pause: %w", err)
+		}
+		return updateStatus(ctx, tx, id, "active", nil, expectedVersions)
+	})
+}
+
+// changeStatus runs a lifecycle change in a transaction and explains a
+// subscription that could not be updated.
+func (r *SubscriptionRepository) changeStatus(ctx context.Context, id uuid.UUID, expectedVersions []int, change func(tx pgx.Tx) (SubscriptionEntity, error)) (SubscriptionEntity, error) {
+	var updated SubscriptionEntity
+	err := pgx.BeginFunc(ctx, conn(ctx, r.pool), func(tx pgx.Tx) error {
+		var err error
+		updated, err = change(tx)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Warn("subscription not found for status change",
+				slog.String("subscription_id", id.String()),
+			)
+			return SubscriptionEntity{}, r.missingRowError(ctx, id)
+		}
+		r.logger.Error("failed to change subscription status",
+			slog.String("subscription_id", id.String()),
+			slog.Any("error", err),
+		)
+		return SubscriptionEntity{}, fmt.Errorf("failed to change subscription status: %w", err)
 	}
 
-	if !startDate.IsZero() {
-		query += fmt.Sprintf(" AND start_date >= $%d", argPos)
-		args = append(args, startDate)
-		argPos++
+	r.logger.Info("subscription status changed",
+		slog.String("subscription_id", id.String()),
+		slog.String("status", updated.Status),
+	)
+	return updated, nil
+}
+
+func updateStatus(ctx context.Context, q rowQuerier, id uuid.UUID, status string, endDate *time.Time, expectedVersions []int) (SubscriptionEntity, error) {
+	query := `
+		UPDATE subscriptions
+		SET status = $2, end_date = COALESCE($3::date, end_date), version = version + 1, updated_at = now()
+		WHERE subscription_id = $1 AND deleted_at IS NULL AND ($4::integer[] IS NULL OR version = ANY($4))
+		RETURNING ` + subscriptionColumns
+
+	return scanSubscription(q.QueryRow(ctx, query, id, status, endDate, expectedVersions))
+}
+
+// PurgeDeleted permanently removes the subscriptions deleted before the
+// given time and returns how many were removed.
+func (r *SubscriptionRepository) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	result, err := conn(ctx, r.pool).Exec(ctx, `DELETE FROM subscriptions WHERE deleted_at < $1`, deletedBefore)
+	if err != nil {
+		r.logger.Error("failed to purge deleted subscriptions",
+			slog.Time("deleted_before", deletedBefore),
+			slog.Any("error", err),
+		)
+		return 0, fmt.Errorf("failed to purge deleted subscriptions: %w", err)
 	}
 
-	if !endDate.IsZero() {
-		query += fmt.Sprintf(" AND end_date <= $%d", argPos)
-		args = append(args, endDate)
-		argPos++
+	r.logger.Info("deleted subscriptions purged",
+		slog.Time("deleted_before", deletedBefore),
+		slog.Int64("count", result.RowsAffected()),
+	)
+	return result.RowsAffected(), nil
+}
+
+// sortKeys maps a sort field to its SQL key expression and the type its
+// cursor value is cast to.
+var sortKeys = map[string]struct {
+	expr     string
+	castType string
+}{
+	"price":        {currentPriceExpr, "integer"},
+	"start_date":   {"start_date", "date"},
+	"end_date":     {"COALESCE(end_date, 'infinity'::date)", "date"},
+	"service_name": {"service_name", "varchar"},
+}
+
+func (r *SubscriptionRepository) GetSubscriptionsList(ctx context.Context, filter SubscriptionListFilter) ([]SubscriptionEntity, *ListCursor, error) {
+	key, ok := sortKeys[filter.SortBy]
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported sort field '%s'", filter.SortBy)
 	}
+	direction, comparison := "ASC", ">"
+	if filter.Order == "desc" {
+		direction, comparison = "DESC", "<"
+	}
+
+	where, args := subscriptionListWhere(filter)
+
+	if filter.After != nil {
+		where += fmt.Sprintf(" AND (%s, subscription_id) %s ($%d::text::%s, $%d)",
+			key.expr, comparison, len(args)+1, key.castType, len(args)+2)
+		args = append(args, filter.After.SortValue, filter.After.SubscriptionID)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM subscriptions
+		%s
+		ORDER BY %s %s, subscription_id %s
+		LIMIT $%d`,
+		subscriptionColumns, where, key.expr, direction, direction, len(args)+1,
+	)
+	// One extra row tells whether there is a next page.
+	args = append(args, filter.Limit+1)
 
-	rows, err := r.pool.Query(ctx, query, args...)
+	rows, err := conn(ctx, r.pool).Query(ctx, query, args...)
 	if err != nil {
 		r.logger.Error("failed to execute list query",
 			slog.Any("error", err),
-			slog.String("user_id", userID.String()),
+			slog.String("user_id", filter.UserID.String()),
 		)
-		return nil, fmt.Errorf("failed to fetch subscriptions: %w", err)
+		return nil, nil, fmt.Errorf("failed to fetch subscriptions: %w", err)
 	}
 	defer rows.Close()
 
 	var subscriptions []SubscriptionEntity
 	for rows.Next() {
-		var sub SubscriptionEntity
-		err := rows.Scan(
-			&sub.SubscriptionID,
-			&sub.ServiceName,
-			&sub.Price,
-			&sub.UserID,
-			&sub.StartDate,
-			&sub.EndDate,
-		)
+		sub, err := scanSubscription(rows)
 		if err != nil {
 			r.logger.Error("failed to scan subscription row",
 				slog.Any("error", err),
 			)
-			return nil, fmt.Errorf("failed to scan subscription: %w", err)
+			return nil, nil, fmt.Errorf("failed to scan subscription: %w", err)
 		}
 		subscriptions = append(subscriptions, sub)
 	}
@@ -295,13 +623,559 @@ func (r *SubscriptionRepository) GetSubscriptionsList(ctx context.Context, servi
 		r.logger.Error("row iteration error",
 			slog.Any("error", err),
 		)
-		return nil, fmt.Errorf("subscription iteration failed: %w", err)
+		return nil, nil, fmt.Errorf("subscription iteration failed: %w", err)
+	}
+
+	var next *ListCursor
+	if len(subscriptions) > filter.Limit {
+		subscriptions = subscriptions[:filter.Limit]
+		last := subscriptions[len(subscriptions)-1]
+		next = &ListCursor{
+			SortValue:      sortValue(last, filter.SortBy),
+			SubscriptionID: last.SubscriptionID,
+		}
 	}
 
 	r.logger.Debug("subscriptions list fetched",
-		slog.String("user_id", userID.String()),
+		slog.String("user_id", filter.UserID.String()),
 		slog.Int("count", len(subscriptions)),
 	)
 
+	return subscriptions, next, nil
+}
+
+// StreamSubscriptions calls fn for every subscription matching the filter in
+// start date order, reading rows as they arrive. Sorting and paging fields
+// of the filter are ignored. Iteration stops at the first error of fn.
+func (r *SubscriptionRepository) StreamSubscriptions(ctx context.Context, filter SubscriptionListFilter, fn func(SubscriptionEntity) error) error {
+	where, args := subscriptionListWhere(filter)
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM subscriptions
+		%s
+		ORDER BY start_date, subscription_id`,
+		subscriptionColumns, where,
+	)
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("failed to execute stream query",
+			slog.String("user_id", filter.UserID.String()),
+			slog.Any("error", err),
+		)
+		return fmt.Errorf("failed to fetch subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan subscription: %w", err)
+		}
+		if err := fn(sub); err != nil {
+			return err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("row iteration error",
+			slog.Any("error", err),
+		)
+		return fmt.Errorf("subscription iteration failed: %w", err)
+	}
+
+	r.logger.Debug("subscriptions streamed",
+		slog.String("user_id", filter.UserID.String()),
+		slog.Int("count", count),
+	)
+	return nil
+}
+
+func (r *SubscriptionRepository) CountSubscriptions(ctx context.Context, filter SubscriptionListFilter) (int64, error) {
+	where, args := subscriptionListWhere(filter)
+	query := `SELECT COUNT(*) FROM subscriptions ` + where
+
+	var count int64
+	if err := conn(ctx, r.pool).QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		r.logger.Error("failed to count subscriptions",
+			slog.String("user_id", filter.UserID.String()),
+			slog.Any("error", err),
+		)
+		return 0, fmt.Errorf("failed to count subscriptions: %w", err)
+	}
+
+	return count, nil
+}
+
+func subscriptionListWhere(filter SubscriptionListFilter) (string, []interface{}) {
+	query := `WHERE deleted_at IS NULL`
+	if filter.Deleted {
+		query = `WHERE deleted_at IS NOT NULL`
+	}
+
+	var args []interface{}
+	argPos := 1
+
+	// A nil user ID lists the subscriptions of every user.
+	if filter.UserID != uuid.Nil {
+		query += fmt.Sprintf(" AND user_id = $%d", argPos)
+		args = append(args, filter.UserID)
+		argPos++
+	}
+
+	if filter.ServiceName != "" {
+		switch filter.ServiceNameMatch {
+		case "iexact":
+			query += fmt.Sprintf(" AND lower(service_name) = lower($%d)", argPos)
+			args = append(args, filter.ServiceName)
+		case "prefix":
+			query += fmt.Sprintf(" AND service_name ILIKE $%d", argPos)
+			args = append(args, escapeLike(filter.ServiceName)+"%")
+		case "contains":
+			query += fmt.Sprintf(" AND service_name ILIKE $%d", argPos)
+			args = append(args, "%"+escapeLike(filter.ServiceName)+"%")
+		default:
+			query += fmt.Sprintf(" AND service_name = $%d", argPos)
+			args = append(args, filter.ServiceName)
+		}
+		argPos++
+	}
+
+	if filter.PeriodMode == "overlaps" {
+		if !filter.StartDate.IsZero() {
+			query += fmt.Sprintf(" AND (end_date IS NULL OR end_date >= date_trunc('month', $%d::timestamp))", argPos)
+			args = append(args, filter.StartDate)
+			argPos++
+		}
+
+		if !filter.EndDate.IsZero() {
+			query += fmt.Sprintf(" AND start_date < date_trunc('month', $%d::timestamp) + interval '1 month'", argPos)
+			args = append(args, filter.EndDate)
+			argPos++
+		}
+	} else {
+		if !filter.StartDate.IsZero() {
+			query += fmt.Sprintf(" AND start_date >= $%d", argPos)
+			args = append(args, filter.StartDate)
+			argPos++
+		}
+
+		if !filter.EndDate.IsZero() {
+			query += fmt.Sprintf(" AND (end_date IS NULL OR end_date <= $%d)", argPos)
+			args = append(args, filter.EndDate)
+			argPos++
+		}
+	}
+
+	if filter.ActiveAt != nil {
+		query += fmt.Sprintf(" AND start_date < date_trunc('month', $%d::timestamp) + interval '1 month'", argPos)
+		query += fmt.Sprintf(" AND (end_date IS NULL OR end_date >= date_trunc('month', $%d::timestamp))", argPos)
+		args = append(args, *filter.ActiveAt)
+		argPos++
+	}
+
+	if filter.MinPrice != nil {
+		query += fmt.Sprintf(" AND %s >= $%d", currentPriceExpr, argPos)
+		args = append(args, *filter.MinPrice)
+		argPos++
+	}
+
+	if filter.MaxPrice != nil {
+		query += fmt.Sprintf(" AND %s <= $%d", currentPriceExpr, argPos)
+		args = append(args, *filter.MaxPrice)
+		argPos++
+	}
+
+	if filter.HasEndDate != nil {
+		if *filter.HasEndDate {
+			query += " AND end_date IS NOT NULL"
+		} else {
+			query += " AND end_date IS NULL"
+		}
+	}
+
+	return query, args
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// sortValue renders the sort key of a row the way it is cast back in
+// GetSubscriptionsList.
+func sortValue(entity SubscriptionEntity, sortBy string) string {
+	switch sortBy {
+	case "price":
+		return strconv.Itoa(entity.CurrentPrice)
+	case "end_date":
+		if entity.EndDate == nil {
+			return "infinity"
+		}
+		return entity.EndDate.Format(time.DateOnly)
+	case "service_name":
+		return entity.ServiceName
+	}
+	return entity.StartDate.Format(time.DateOnly)
+}
+
+func (r *SubscriptionRepository) GetTotalCost(ctx context.Context, filter CostFilter) (int64, error) {
+	from, args := activeMonthsFrom(filter)
+	query := fmt.Sprintf(`SELECT ROUND(COALESCE(SUM(%s), 0))::bigint, %s`, convertedCostExpr(filter.Basis), missingRateExpr) + from
+
+	var total int64
+	var missingRates bool
+	if err := conn(ctx, r.pool).QueryRow(ctx, query, args...).Scan(&total, &missingRates); err != nil {
+		r.logger.Error("failed to calculate total cost",
+			slog.String("user_id", filter.UserID.String()),
+			slog.String("service_name", filter.ServiceName),
+			slog.Any("error", err),
+		)
+		return 0, fmt.Errorf("failed to calculate total cost: %w", err)
+	}
+
+	if missingRates {
+		r.logger.Warn("exchange rate missing for total cost",
+			slog.String("user_id", filter.UserID.String()),
+			slog.String("currency", filter.Currency),
+		)
+		return 0, fmt.Errorf("no exchange rate available to convert into %s: %w", filter.Currency, ErrMissingExchangeRate)
+	}
+
+	r.logger.Debug("total cost calculated",
+		slog.String("user_id", filter.UserID.String()),
+		slog.Int64("total", total),
+	)
+	return total, nil
+}
+
+func (r *SubscriptionRepository) GetMonthlySpend(ctx context.Context, filter CostFilter) ([]MonthlyServiceSpendEntity, error) {
+	from, args := activeMonthsFrom(filter)
+	query := fmt.Sprintf(`SELECT m.month::date, s.service_name, ROUND(SUM(%s))::bigint, %s`, convertedCostExpr(filter.Basis), missingRateExpr) + from + `
+		GROUP BY m.month, s.service_name
+		ORDER BY m.month, s.service_name`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("failed to execute monthly spend query",
+			slog.String("user_id", filter.UserID.String()),
+			slog.Any("error", err),
+		)
+		return nil, fmt.Errorf("failed to fetch monthly spend: %w", err)
+	}
+	defer rows.Close()
+
+	var spend []MonthlyServiceSpendEntity
+	for rows.Next() {
+		var entity MonthlyServiceSpendEntity
+		var missingRates bool
+		if err := rows.Scan(&entity.Month, &entity.ServiceName, &entity.Total, &missingRates); err != nil {
+			r.logger.Error("failed to scan monthly spend row",
+				slog.Any("error", err),
+			)
+			return nil, fmt.Errorf("failed to scan monthly spend: %w", err)
+		}
+		if missingRates {
+			r.logger.Warn("exchange rate missing for monthly spend",
+				slog.String("user_id", filter.UserID.String()),
+				slog.String("currency", filter.Currency),
+				slog.Time("month", entity.Month),
+			)
+			return nil, fmt.Errorf("no exchange rate available to convert into %s: %w", filter.Currency, ErrMissingExchangeRate)
+		}
+		spend = append(spend, entity)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.logger.Error("row iteration error",
+			slog.Any("error", err),
+		)
+		return nil, fmt.Errorf("monthly spend iteration failed: %w", err)
+	}
+
+	r.logger.Debug("monthly spend fetched",
+		slog.String("user_id", filter.UserID.String()),
+		slog.Int("count", len(spend)),
+	)
+	return spend, nil
+}
+
+// activeMonthsFrom builds a FROM clause that yields one row per subscription
+// and per month of the filter period in which the subscription is active and
+// not paused.
+// Months are exposed as m.month (first day of month), subscriptions as s,
+// the scheduled price in effect for the month (if any) as p, and the
+// exchange rates in effect at the start of the month for the subscription
+// currency and the target currency ($4) as src and dst.
+func activeMonthsFrom(filter CostFilter) (string, []interface{}) {
+	query := `
+		FROM generate_series(date_trunc('month', $2::timestamp), date_trunc('month', $3::timestamp), interval '1 month') AS m(month)
+		JOIN subscriptions s
+			ON s.deleted_at IS NULL
+			AND s.start_date < m.month + interval '1 month'
+			AND (s.end_date IS NULL OR s.end_date >= m.month)
+			AND NOT EXISTS (
+				SELECT 1 FROM subscription_pauses sp
+				WHERE sp.subscription_id = s.subscription_id
+					AND m.month BETWEEN sp.start_month AND sp.end_month
+			)
+		LEFT JOIN LATERAL (
+			SELECT price FROM subscription_prices
+			WHERE subscription_id = s.subscription_id AND effective_from <= m.month
+			ORDER BY effective_from DESC LIMIT 1
+		) p ON true
+		LEFT JOIN LATERAL (
+			SELECT rate FROM exchange_rates
+			WHERE currency = s.currency AND effective_from <= m.month
+			ORDER BY effective_from DESC LIMIT 1
+		) src ON s.currency <> $4
+		LEFT JOIN LATERAL (
+			SELECT rate FROM exchange_rates
+			WHERE currency = $4 AND effective_from <= m.month
+			ORDER BY effective_from DESC LIMIT 1
+		) dst ON s.currency <> $4
+		WHERE s.user_id = $1`
+
+	args := []interface{}{filter.UserID, filter.StartDate, filter.EndDate, filter.Currency}
+
+	if filter.ServiceName != "" {
+		query += fmt.Sprintf(" AND s.service_name = $%d", len(args)+1)
+		args = append(args, filter.ServiceName)
+	}
+
+	return query, args
+}
+
+// missingRateExpr reports whether any row of the aggregate needed a currency
+// conversion for which no exchange rate was in effect.
+const missingRateExpr = `COALESCE(bool_or(s.currency <> $4 AND (src.rate IS NULL OR dst.rate IS NULL)), false)`
+
+func convertedCostExpr(basis string) string {
+	return fmt.Sprintf(`(%s) * CASE WHEN s.currency = $4 THEN 1 ELSE src.rate / dst.rate END`, costExpr(basis))
+}
+
+// monthsSinceStart is the number of whole calendar months between the
+// subscription start and the current month of the series.
+const monthsSinceStart = `((EXTRACT(YEAR FROM m.month) - EXTRACT(YEAR FROM s.start_date)) * 12 + EXTRACT(MONTH FROM m.month) - EXTRACT(MONTH FROM s.start_date))`
+
+// costExpr returns the cost of subscription s in month m.month. The "charged"
+// basis counts the charge dates falling into the month, any other basis uses
+// the monthly equivalent of the price.
+func costExpr(basis string) string {
+	if basis == "charged" {
+		return `COALESCE(p.price, s.price) * CASE s.billing_period
+			WHEN 'weekly' THEN CEIL(((m.month + interval '1 month')::date - s.start_date) / 7.0)
+				- CEIL((GREATEST(m.month::date, s.start_date) - s.start_date) / 7.0)
+			WHEN 'quarterly' THEN CASE WHEN MOD(` + monthsSinceStart + `, 3) = 0 THEN 1 ELSE 0 END
+			WHEN 'yearly' THEN CASE WHEN MOD(` + monthsSinceStart + `, 12) = 0 THEN 1 ELSE 0 END
+			ELSE 1
+		END`
+	}
+
+	return `COALESCE(p.price, s.price) * CASE s.billing_period
+			WHEN 'weekly' THEN 52.0 / 12
+			WHEN 'quarterly' THEN 1.0 / 3
+			WHEN 'yearly' THEN 1.0 / 12
+			ELSE 1
+		END`
+}
+
+// missingRowError explains why a conditional write matched no row: either
+// the subscription does not exist or its version did not match.
+func (r *SubscriptionRepository) missingRowError(ctx context.Context, id uuid.UUID) error {
+	var exists bool
+	if err := conn(ctx, r.pool).QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM subscriptions WHERE subscription_id = $1 AND deleted_at IS NULL)`, id).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check subscription existence: %w", err)
+	}
+	if exists {
+		return fmt.Errorf("subscription %s: %w", id, ErrVersionMismatch)
+	}
+	return fmt.Errorf("subscription %s: %w", id, ErrNotFound)
+}
+
+// rowQuerier is satisfied by both the pool and a transaction.
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func scanSubscription(row pgx.Row) (SubscriptionEntity, error) {
+	var entity SubscriptionEntity
+	err := row.Scan(
+		&entity.SubscriptionID,
+		&entity.ServiceName,
+		&entity.Price,
+		&entity.CurrentPrice,
+		&entity.UserID,
+		&entity.StartDate,
+		&entity.EndDate,
+		&entity.BillingPeriod,
+		&entity.Currency,
+		&entity.Status,
+		&entity.Version,
+		&entity.UpdatedAt,
+		&entity.DeletedAt,
+	)
+	return entity, err
+}
+
+// BumpVersion gives the subscription a new version without changing its
+// columns, for changes stored elsewhere such as a change of its current
+// price.
+func (r *SubscriptionRepository) BumpVersion(ctx context.Context, id uuid.UUID, expectedVersions []int) (SubscriptionEntity, error) {
+	return r.UpdatePatch(ctx, id, nil, expectedVersions)
+}
+
+func (r *SubscriptionRepository) UpsertPrice(ctx context.Context, price SubscriptionPriceEntity) error {
+	query := `
+		INSERT INTO subscription_prices (subscription_id, price, effective_from)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (subscription_id, effective_from) DO UPDATE SET price = EXCLUDED.price
+	`
+
+	if _, err := conn(ctx, r.pool).Exec(ctx, query, price.SubscriptionID, price.Price, price.EffectiveFrom); err != nil {
+		r.logger.Error("failed to upsert subscription price",
+			slog.String("subscription_id", price.SubscriptionID.String()),
+			slog.Any("error", err),
+		)
+		return fmt.Errorf("failed to upsert subscription price: %w", classifyError(err))
+	}
+
+	r.logger.Info("subscription price scheduled",
+		slog.String("subscription_id", price.SubscriptionID.String()),
+		slog.Time("effective_from", price.EffectiveFrom),
+	)
+	return nil
+}
+
+func (r *SubscriptionRepository) GetPriceHistory(ctx context.Context, id uuid.UUID) ([]SubscriptionPriceEntity, error) {
+	query := `
+		SELECT subscription_id, price, effective_from
+		FROM subscription_prices
+		WHERE subscription_id = $1
+		ORDER BY effective_from
+	`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, id)
+	if err != nil {
+		r.logger.Error("failed to execute price history query",
+			slog.String("subscription_id", id.String()),
+			slog.Any("error", err),
+		)
+		return nil, fmt.Errorf("failed to fetch price history: %w", err)
+	}
+	defer rows.Close()
+
+	var prices []SubscriptionPriceEntity
+	for rows.Next() {
+		var price SubscriptionPriceEntity
+		if err := rows.Scan(&price.SubscriptionID, &price.Price, &price.EffectiveFrom); err != nil {
+			r.logger.Error("failed to scan subscription price row",
+				slog.Any("error", err),
+			)
+			return nil, fmt.Errorf("failed to scan subscription price: %w", err)
+		}
+		prices = append(prices, price)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.logger.Error("row iteration error",
+			slog.Any("error", err),
+		)
+		return nil, fmt.Errorf("price history iteration failed: %w", err)
+	}
+
+	return prices, nil
+}
+
+// GetActiveSubscriptions returns the subscriptions of a user that are active
+// at some point between from and to.
+func (r *SubscriptionRepository) GetActiveSubscriptions(ctx context.Context, userID uuid.UUID, from time.Time, to time.Time) ([]SubscriptionEntity, error) {
+	query := `
+		SELECT ` + subscriptionColumns + `
+		FROM subscriptions
+		WHERE user_id = $1
+			AND deleted_at IS NULL
+			AND start_date <= $3
+			AND (end_date IS NULL OR end_date >= date_trunc('month', $2::timestamp))
+		ORDER BY start_date`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, userID, from, to)
+	if err != nil {
+		r.logger.Error("failed to execute active subscriptions query",
+			slog.String("user_id", userID.String()),
+			slog.Any("error", err),
+		)
+		return nil, fmt.Errorf("failed to fetch active subscriptions: %w", err)
+	}
+
+	subscriptions, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (SubscriptionEntity, error) {
+		return scanSubscription(row)
+	})
+	if err != nil {
+		r.logger.Error("failed to collect active subscriptions",
+			slog.String("user_id", userID.String()),
+			slog.Any("error", err),
+		)
+		return nil, fmt.Errorf("failed to scan active subscriptions: %w", err)
+	}
+
 	return subscriptions, nil
-}
\ No newline at end of file
+}
+
+// GetPausesForSubscriptions returns the pauses of the given subscriptions
+// ordered by subscription and start month.
+func (r *SubscriptionRepository) GetPausesForSubscriptions(ctx context.Context, ids []uuid.UUID) ([]SubscriptionPauseEntity, error) {
+	query := `
+		SELECT subscription_id, start_month, end_month
+		FROM subscription_pauses
+		WHERE subscription_id = ANY($1)
+		ORDER BY subscription_id, start_month
+	`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, ids)
+	if err != nil {
+		r.logger.Error("failed to execute subscription pauses query",
+			slog.Int("subscriptions", len(ids)),
+			slog.Any("error", err),
+		)
+		return nil, fmt.Errorf("failed to fetch subscription pauses: %w", err)
+	}
+
+	pauses, err := pgx.CollectRows(rows, pgx.RowToStructByName[SubscriptionPauseEntity])
+	if err != nil {
+		r.logger.Error("failed to collect subscription pauses",
+			slog.Any("error", err),
+		)
+		return nil, fmt.Errorf("failed to scan subscription pauses: %w", err)
+	}
+
+	return pauses, nil
+}
+
+func (r *SubscriptionRepository) GetPricesForSubscriptions(ctx context.Context, ids []uuid.UUID) ([]SubscriptionPriceEntity, error) {
+	query := `
+		SELECT subscription_id, price, effective_from
+		FROM subscription_prices
+		WHERE subscription_id = ANY($1)
+		ORDER BY subscription_id, effective_from
+	`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, ids)
+	if err != nil {
+		r.logger.Error("failed to execute subscription prices query",
+			slog.Int("subscriptions", len(ids)),
+			slog.Any("error", err),
+		)
+		return nil, fmt.Errorf("failed to fetch subscription prices: %w", err)
+	}
+
+	prices, err := pgx.CollectRows(rows, pgx.RowToStructByName[SubscriptionPriceEntity])
+	if err != nil {
+		r.logger.Error("failed to collect subscription prices",
+			slog.Any("error", err),
+		)
+		return nil, fmt.Errorf("failed to scan subscription prices: %w", err)
+	}
+
+	return prices, nil
+}
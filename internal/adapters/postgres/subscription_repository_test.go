@@ -0,0 +1,41 @@
+package postgres
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestSubscriptionListWhereKeepsOpenEndedSubscriptions(t *testing.T) {
+	start := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, time.December, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		periodMode string
+		want       string
+	}{
+		{name: "contains", periodMode: "contains", want: "(end_date IS NULL OR end_date <= $3)"},
+		{name: "default mode", want: "(end_date IS NULL OR end_date <= $3)"},
+		{name: "overlaps", periodMode: "overlaps", want: "(end_date IS NULL OR end_date >= date_trunc('month', $2::timestamp))"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, args := subscriptionListWhere(SubscriptionListFilter{
+				UserID:     uuid.New(),
+				StartDate:  start,
+				EndDate:    end,
+				PeriodMode: tt.periodMode,
+			})
+			if !strings.Contains(where, tt.want) {
+				t.Errorf("where = %q, want it to contain %q", where, tt.want)
+			}
+			if len(args) != 3 {
+				t.Errorf("args = %v, want user ID, start and end", args)
+			}
+		})
+	}
+}
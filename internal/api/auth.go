@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	api_models "github.com/kgugunava/effective_mobile_golang/internal/api/models"
+	"github.com/kgugunava/effective_mobile_golang/internal/auth"
+	"github.com/kgugunava/effective_mobile_golang/internal/domain"
+	"github.com/kgugunava/effective_mobile_golang/internal/requestctx"
+)
+
+const (
+	bearerScheme = "Bearer"
+	apiKeyScheme = "ApiKey"
+)
+
+// APIKeyVerifier resolves an API key to the principal it was issued for. It
+// returns auth.ErrInvalidAPIKey for keys that cannot be used.
+type APIKeyVerifier interface {
+	VerifyAPIKey(ctx context.Context, key string) (domain.Principal, error)
+}
+
+// Authenticate requires a valid bearer JWT or API key on every request. The
+// caller it identifies is stored in the gin context and the request context,
+// and is reported as the actor of the request, replacing any X-Actor header.
+func Authenticate(verifier *auth.JWTVerifier, apiKeys APIKeyVerifier, logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scheme, credentials, _ := strings.Cut(c.GetHeader("Authorization"), " ")
+		credentials = strings.TrimSpace(credentials)
+
+		var (
+			principal domain.Principal
+			actor     string
+		)
+		switch {
+		case strings.EqualFold(scheme, bearerScheme) && credentials != "":
+			var err error
+			if principal, err = verifier.Verify(credentials); err != nil {
+				abortUnauthenticated(c, "INVALID_TOKEN", "the bearer token is invalid or expired")
+				return
+			}
+			actor = principal.UserID.String()
+		case strings.EqualFold(scheme, apiKeyScheme) && credentials != "":
+			var err error
+			if principal, err = apiKeys.VerifyAPIKey(c.Request.Context(), credentials); err != nil {
+				if errors.Is(err, auth.ErrInvalidAPIKey) {
+					abortUnauthenticated(c, "INVALID_API_KEY", "the API key is invalid, revoked or expired")
+					return
+				}
+				logger.Error("failed to verify API key",
+					slog.String("method", c.Request.Method),
+					slog.String("path", c.FullPath()),
+					slog.Any("error", err),
+				)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, api_models.ErrorResponse{
+					Error: api_models.ErrorResponseError{
+						Code:    "INTERNAL_ERROR",
+						Message: "internal server error",
+					},
+				})
+				return
+			}
+			actor = "api_key:" + principal.APIKeyID.String()
+		default:
+			abortUnauthenticated(c, "UNAUTHENTICATED", "a bearer token or an API key is required")
+			return
+		}
+
+		auth.SetPrincipal(c, principal)
+		ctx := requestctx.WithPrincipal(c.Request.Context(), principal)
+		c.Request = c.Request.WithContext(requestctx.WithActor(ctx, actor))
+
+		c.Next()
+	}
+}
+
+func abortUnauthenticated(c *gin.Context, code string, message string) {
+	c.Header("WWW-Authenticate", bearerScheme+", "+apiKeyScheme)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, api_models.ErrorResponse{
+		Error: api_models.ErrorResponseError{
+			Code:    code,
+			Message: message,
+		},
+	})
+}
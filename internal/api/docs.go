@@ -0,0 +1,59 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kgugunava/effective_mobile_golang/internal/api/openapi"
+)
+
+// OpenAPISpec serves the OpenAPI document of the v1 API.
+func OpenAPISpec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", openapi.Spec)
+}
+
+// SwaggerUI serves the page rendering the OpenAPI document.
+func SwaggerUI(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", openapi.SwaggerUI)
+}
+
+// checkRoutesDocumented reports routes missing from the OpenAPI document and
+// operations of the document that are not routed, so the two cannot drift
+// apart unnoticed.
+func checkRoutesDocumented(routes []Route) error {
+	operations, err := openapi.Operations()
+	if err != nil {
+		return err
+	}
+
+	documented := make(map[string]openapi.Operation, len(operations))
+	for _, op := range operations {
+		documented[op.Method+" "+op.Path] = op
+	}
+
+	var problems []string
+	for _, route := range routes {
+		key := route.Method + " " + openapi.PathFromPattern(route.Pattern)
+		op, ok := documented[key]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("%s (%s) is not documented", key, route.Name))
+		case op.OperationID != route.Name:
+			problems = append(problems, fmt.Sprintf("%s is documented as %q instead of %q", key, op.OperationID, route.Name))
+		}
+		delete(documented, key)
+	}
+	for _, op := range operations {
+		if key := op.Method + " " + op.Path; documented[key] == op {
+			problems = append(problems, fmt.Sprintf("%s is documented but not routed", key))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("openapi document is out of sync with the routes: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
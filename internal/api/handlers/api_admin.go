@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	api_models "github.com/kgugunava/effective_mobile_golang/internal/api/models"
+	service_domain "github.com/kgugunava/effective_mobile_golang/internal/domain"
+)
+
+// AdminSubscriptionListGet lists the subscriptions of every user, or of the
+// user given by user_id. The service restricts it to admins.
+func (api *SubscriptionAPI) AdminSubscriptionListGet(c *gin.Context) {
+	format, ok := api.bindDateFormat(c)
+	if !ok {
+		return
+	}
+
+	api.logger.Info("handling admin subscriptions list request",
+		slog.String("method", "GET"),
+		slog.String("user_id", c.Query("user_id")),
+	)
+
+	query := service_domain.SubscriptionListQuery{
+		ServiceName: c.Query("service_name"),
+	}
+	if !api.bindOptionalUUID(c, "user_id", &query.UserID) {
+		return
+	}
+	if !api.bindListFilterQuery(c, &query) {
+		return
+	}
+	if !api.bindPagingQuery(c, &query) {
+		return
+	}
+
+	page, err := api.subscriptionService.ListAllSubscriptions(c.Request.Context(), query)
+	if err != nil {
+		api.logger.Error("failed to get admin subscriptions list",
+			slog.String("method", "GET"),
+			slog.Any("error", err),
+		)
+		api.writeError(c, err)
+		return
+	}
+
+	c.JSON(200, api_models.SubscriptionListGetResponse200{
+		Subscriptions: transferServiceDomainListToAPIModelList(page.Subscriptions, format),
+		Paging:        transferPageToAPIModelPaging(query, page),
+	})
+}
+
+// AdminHistoryGet lists the changes to the subscriptions of every user,
+// optionally narrowed by user_id and subscription_id. The service restricts
+// it to admins.
+func (api *SubscriptionAPI) AdminHistoryGet(c *gin.Context) {
+	format, ok := api.bindDateFormat(c)
+	if !ok {
+		return
+	}
+
+	var userID, subscriptionID uuid.UUID
+	if !api.bindOptionalUUID(c, "user_id", &userID) || !api.bindOptionalUUID(c, "subscription_id", &subscriptionID) {
+		return
+	}
+
+	var query service_domain.AuditQuery
+	if userID != uuid.Nil {
+		query.UserID = &userID
+	}
+	if subscriptionID != uuid.Nil {
+		query.SubscriptionID = &subscriptionID
+	}
+	if !api.bindAuditPagingQuery(c, &query) {
+		return
+	}
+
+	page, err := api.subscriptionService.GetAllAuditHistory(c.Request.Context(), query)
+	if err != nil {
+		api.logger.Error("failed to get admin audit history",
+			slog.String("method", "GET"),
+			slog.Any("error", err),
+		)
+		api.writeError(c, err)
+		return
+	}
+
+	c.JSON(200, transferAuditPageToAPIModel(page, format))
+}
+
+// bindOptionalUUID parses the query parameter name into *id when it is set.
+// It writes a 400 response and returns false when it is not a valid UUID.
+func (api *SubscriptionAPI) bindOptionalUUID(c *gin.Context, name string, id *uuid.UUID) bool {
+	value := c.Query(name)
+	if value == "" {
+		return true
+	}
+
+	parsed, err := uuid.Parse(value)
+	if err != nil {
+		api.logger.Warn("invalid ID format in query",
+			slog.String("path", c.FullPath()),
+			slog.String(name, value),
+		)
+		api.writeError(c, service_domain.InvalidInputError("INVALID_ID", "invalid %s format", name))
+		return false
+	}
+	*id = parsed
+	return true
+}
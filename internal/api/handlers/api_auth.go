@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	api_models "github.com/kgugunava/effective_mobile_golang/internal/api/models"
+	"github.com/kgugunava/effective_mobile_golang/internal/auth"
+	service_domain "github.com/kgugunava/effective_mobile_golang/internal/domain"
+)
+
+// authenticatedUser returns the ID of the user the request was authenticated
+// as, which is nil for API keys. It writes a 401 response and returns false
+// when the route is not behind authentication.
+func (api *SubscriptionAPI) authenticatedUser(c *gin.Context) (uuid.UUID, bool) {
+	principal, ok := auth.PrincipalFrom(c)
+	if !ok {
+		api.logger.Error("request reached a handler without authentication",
+			slog.String("path", c.FullPath()),
+		)
+		c.JSON(401, api_models.ErrorResponse{
+			Error: api_models.ErrorResponseError{
+				Code:    "UNAUTHENTICATED",
+				Message: "authentication is required",
+			},
+		})
+		return uuid.Nil, false
+	}
+	return principal.UserID, true
+}
+
+// bindUserID resolves the user_id a request is about, defaulting to the
+// authenticated user. API keys do not act as a user and have to name one.
+// Whether the caller may access that user is decided by the service. It
+// writes an error response and returns false when the user ID is missing or
+// invalid.
+func (api *SubscriptionAPI) bindUserID(c *gin.Context, userIDStr string) (uuid.UUID, bool) {
+	authenticated, ok := api.authenticatedUser(c)
+	if !ok {
+		return uuid.Nil, false
+	}
+	if userIDStr == "" {
+		if authenticated == uuid.Nil {
+			api.writeError(c, service_domain.InvalidInputError("USER_ID_REQUIRED", "user_id is required for API key callers"))
+			return uuid.Nil, false
+		}
+		return authenticated, true
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		api.logger.Warn("invalid user ID format",
+			slog.String("path", c.FullPath()),
+			slog.String("user_id", userIDStr),
+		)
+		c.JSON(400, api_models.ErrorResponse{
+			Error: api_models.ErrorResponseError{
+				Code:    "INVALID_ID",
+				Message: "invalid user ID format",
+			},
+		})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// defaultUserID sets a nil *userID to the authenticated user.
+func defaultUserID(authenticated uuid.UUID, userID *uuid.UUID) {
+	if *userID == uuid.Nil {
+		*userID = authenticated
+	}
+}
+
+// defaultBulkUserIDs applies defaultUserID to every item that could be
+// parsed.
+func defaultBulkUserIDs(authenticated uuid.UUID, items []service_domain.BulkCreateItem) {
+	for i := range items {
+		if items[i].Err != nil {
+			continue
+		}
+		defaultUserID(authenticated, &items[i].Subscription.UserID)
+	}
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	api_models "github.com/kgugunava/effective_mobile_golang/internal/api/models"
+	service_domain "github.com/kgugunava/effective_mobile_golang/internal/domain"
+)
+
+// csvColumns are the columns of an exported file, matching the fields of
+// api_models.Subscription.
+var csvColumns = []string{
+	"subscription_id",
+	"service_name",
+	"price",
+	"user_id",
+	"start_date",
+	"end_date",
+	"billing_period",
+	"currency",
+	"status",
+	"version",
+	"updated_at",
+}
+
+// csvRequiredColumns must be present in an imported file. Other known
+// columns are optional and the server generated ones are ignored, so an
+// exported file can be imported as is. Rows without a user_id belong to the
+// importing user.
+var csvRequiredColumns = []string{"service_name", "price", "start_date"}
+
+const maxImportRows = 5000
+
+func transferAPIModelToCSVRecord(s api_models.Subscription) []string {
+	return []string{
+		s.SubscriptionID.String(),
+		s.ServiceName,
+		strconv.Itoa(s.Price),
+		s.UserID.String(),
+		s.StartDate,
+		s.EndDate,
+		s.BillingPeriod,
+		s.Currency,
+		s.Status,
+		strconv.Itoa(s.Version),
+		s.UpdatedAt,
+	}
+}
+
+// csvImportRow is a parsed data row of an imported file with the line it
+// starts on.
+type csvImportRow struct {
+	Line int
+	Item service_domain.BulkCreateItem
+}
+
+// readCSVImport parses an imported file. Rows that cannot be mapped to a
+// subscription are returned with their error set; an error is returned only
+// when the file as a whole is unusable.
+func readCSVImport(r io.Reader) ([]csvImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, service_domain.InvalidInputError("INVALID_CSV", "file is empty, expected a header row")
+	}
+	if err != nil {
+		return nil, service_domain.InvalidInputError("INVALID_CSV", "malformed header row: %s", err.Error())
+	}
+
+	index := map[string]int{}
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range csvRequiredColumns {
+		if _, ok := index[name]; !ok {
+			return nil, service_domain.InvalidInputError("INVALID_CSV", "missing required column %q", name)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := index[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var rows []csvImportRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) && errors.Is(parseErr.Err, csv.ErrFieldCount) {
+			rows = append(rows, csvImportRow{Line: parseErr.StartLine, Item: service_domain.BulkCreateItem{
+				Err: service_domain.InvalidInputError("INVALID_ROW", "expected %d fields, got %d", len(header), len(record)),
+			}})
+			continue
+		}
+		if err != nil {
+			return nil, service_domain.InvalidInputError("INVALID_CSV", "%s", err.Error())
+		}
+
+		line, _ := reader.FieldPos(0)
+		if len(rows) == maxImportRows {
+			return nil, service_domain.InvalidInputError("INVALID_CSV", "file must contain at most %d rows", maxImportRows)
+		}
+		rows = append(rows, csvImportRow{Line: line, Item: transferCSVRecordToServiceDomain(func(name string) string {
+			return field(record, name)
+		})})
+	}
+
+	if len(rows) == 0 {
+		return nil, service_domain.InvalidInputError("INVALID_CSV", "file contains no subscriptions")
+	}
+	return rows, nil
+}
+
+func transferCSVRecordToServiceDomain(field func(name string) string) service_domain.BulkCreateItem {
+	price, err := strconv.Atoi(field("price"))
+	if err != nil {
+		return service_domain.BulkCreateItem{Err: service_domain.InvalidInputError("INVALID_INPUT", "invalid price: %q is not an integer", field("price"))}
+	}
+	var userID uuid.UUID
+	if userIDStr := field("user_id"); userIDStr != "" {
+		if userID, err = uuid.Parse(userIDStr); err != nil {
+			return service_domain.BulkCreateItem{Err: service_domain.InvalidInputError("INVALID_INPUT", "invalid user_id: %q is not a UUID", userIDStr)}
+		}
+	}
+
+	subscription, err := transferCreateRequestToServiceDomain(api_models.SubscriptionCreatePostRequest{
+		ServiceName:   field("service_name"),
+		Price:         price,
+		UserID:        userID,
+		StartDate:     field("start_date"),
+		EndDate:       field("end_date"),
+		BillingPeriod: field("billing_period"),
+		Currency:      field("currency"),
+	})
+	if err != nil {
+		return service_domain.BulkCreateItem{Err: service_domain.InvalidInputError("INVALID_INPUT", "%s", err.Error())}
+	}
+	return service_domain.BulkCreateItem{Subscription: subscription}
+}
+
+func transferImportResultsToAPIModel(rows []csvImportRow, results []service_domain.BulkCreateResult, dryRun bool) api_models.SubscriptionImportPostResponse {
+	resp := api_models.SubscriptionImportPostResponse{
+		DryRun: dryRun,
+		Total:  len(rows),
+		Errors: []api_models.SubscriptionImportError{},
+	}
+	for i, result := range results {
+		switch result.Status {
+		case service_domain.BulkItemCreated:
+			resp.Created++
+			resp.Valid++
+		case service_domain.BulkItemValid, service_domain.BulkItemSkipped:
+			resp.Valid++
+		case service_domain.BulkItemFailed:
+			_, errResp := transferErrorToAPIModel(result.Err)
+			resp.Errors = append(resp.Errors, api_models.SubscriptionImportError{
+				Line:  rows[i].Line,
+				Error: errResp.Error,
+			})
+		}
+	}
+	return resp
+}
+
+func csvExportFilename(userID uuid.UUID) string {
+	return fmt.Sprintf("subscriptions_%s.csv", userID)
+}
+
+// csvResponseWriter writes an exported file to the response, setting its CSV
+// headers on the first write. Until then an error response can still be sent
+// with its own content type.
+type csvResponseWriter struct {
+	c        *gin.Context
+	filename string
+}
+
+func (w csvResponseWriter) Write(p []byte) (int, error) {
+	if !w.c.Writer.Written() {
+		w.c.Header("Content-Type", "text/csv; charset=utf-8")
+		w.c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", w.filename))
+	}
+	return w.c.Writer.Write(p)
+}
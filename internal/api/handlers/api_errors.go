@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	api_models "github.com/kgugunava/effective_mobile_golang/internal/api/models"
+	service_domain "github.com/kgugunava/effective_mobile_golang/internal/domain"
+)
+
+var errorKinds = []struct {
+	kind   error
+	status int
+	code   string
+}{
+	{service_domain.ErrNotFound, http.StatusNotFound, "NOT_FOUND"},
+	{service_domain.ErrInvalidInput, http.StatusBadRequest, "INVALID_INPUT"},
+	{service_domain.ErrValidation, http.StatusUnprocessableEntity, "VALIDATION_ERROR"},
+	{service_domain.ErrConflict, http.StatusConflict, "CONFLICT"},
+	{service_domain.ErrForbidden, http.StatusForbidden, "FORBIDDEN"},
+	{service_domain.ErrPreconditionFailed, http.StatusPreconditionFailed, "PRECONDITION_FAILED"},
+}
+
+// transferErrorToAPIModel maps an error returned by the service layer to an
+// HTTP status and error response. Errors that are not domain errors are
+// reported as 500 without exposing their details.
+func transferErrorToAPIModel(err error) (int, api_models.ErrorResponse) {
+	for _, k := range errorKinds {
+		if !errors.Is(err, k.kind) {
+			continue
+		}
+
+		resp := api_models.ErrorResponse{
+			Error: api_models.ErrorResponseError{
+				Code:    k.code,
+				Message: err.Error(),
+			},
+		}
+
+		var domainErr *service_domain.Error
+		if errors.As(err, &domainErr) {
+			if domainErr.Code != "" {
+				resp.Error.Code = domainErr.Code
+			}
+			for _, detail := range domainErr.Details {
+				resp.Error.Details = append(resp.Error.Details, api_models.ErrorResponseErrorDetail{
+					Field:   detail.Field,
+					Message: detail.Message,
+				})
+			}
+		}
+
+		return k.status, resp
+	}
+
+	return http.StatusInternalServerError, api_models.ErrorResponse{
+		Error: api_models.ErrorResponseError{
+			Code:    "INTERNAL_ERROR",
+			Message: "internal server error",
+		},
+	}
+}
+
+func (api *SubscriptionAPI) writeError(c *gin.Context, err error) {
+	status, resp := transferErrorToAPIModel(err)
+	c.JSON(status, resp)
+}
+
+func bindError(err error) error {
+	return service_domain.InvalidInputError("INVALID_BODY", "malformed request body: %s", err.Error())
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setETag(c *gin.Context, version int) {
+	c.Header("ETag", fmt.Sprintf("%q", strconv.Itoa(version)))
+}
+
+// ifMatchVersions returns the subscription versions listed in the If-Match
+// header, or nil when the header is absent or "*". If-Match uses strong
+// comparison, so weak and malformed tags never match.
+func ifMatchVersions(c *gin.Context) []int {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return nil
+	}
+
+	versions := []int{}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if len(tag) < 2 || !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) {
+			continue
+		}
+		if version, err := strconv.Atoi(tag[1 : len(tag)-1]); err == nil {
+			versions = append(versions, version)
+		}
+	}
+	return versions
+}
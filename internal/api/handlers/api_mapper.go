@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,6 +10,9 @@ import (
 	service_domain "github.com/kgugunava/effective_mobile_golang/internal/domain"
 )
 
+// transferStringMonthYearToDate parses a date given either with day
+// precision as YYYY-MM-DD or as a month (MM-YYYY or YYYY-MM), which stands
+// for the first day of that month.
 func transferStringMonthYearToDate(s string) (time.Time, error) {
 	if t, err := time.Parse("01-2006", s); err == nil {
 		return t, err
@@ -18,7 +22,11 @@ func transferStringMonthYearToDate(s string) (time.Time, error) {
 		return t, err
 	}
 
-	return time.Time{}, fmt.Errorf("invalid date format: %s, expected MM-YYYY or YYYY-MM", s)
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, err
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date format: %s, expected MM-YYYY, YYYY-MM or YYYY-MM-DD", s)
 }
 
 func transferDatetoString(d time.Time) string {
@@ -26,6 +34,23 @@ func transferDatetoString(d time.Time) string {
 	return s
 }
 
+// dateFormat selects how subscription dates are rendered in responses.
+type dateFormat string
+
+const (
+	// dateFormatMonth renders dates as MM-YYYY, dropping the day.
+	dateFormatMonth dateFormat = "month"
+	// dateFormatISO renders dates as YYYY-MM-DD.
+	dateFormatISO dateFormat = "iso"
+)
+
+func transferDateToFormattedString(d time.Time, format dateFormat) string {
+	if format == dateFormatISO {
+		return d.Format(time.DateOnly)
+	}
+	return transferDatetoString(d)
+}
+
 func transferUpdatePutRequestToServiceDomain(req api_models.SubscriptionUpdatePutRequest, id uuid.UUID) (service_domain.Subscription, error) {
 	var startDate time.Time
 	var err error
@@ -50,6 +75,8 @@ func transferUpdatePutRequestToServiceDomain(req api_models.SubscriptionUpdatePu
 		UserID:      req.UserID,
 		StartDate:   startDate,
 		EndDate:     endDate,
+		BillingPeriod: service_domain.BillingPeriod(req.BillingPeriod),
+		Currency: strings.ToUpper(req.Currency),
 	}, nil
 }
 
@@ -74,43 +101,193 @@ func transferCreateRequestToServiceDomain(req api_models.SubscriptionCreatePostR
 		UserID:      req.UserID,
 		StartDate:   startDate,
 		EndDate:     endDate,
+		BillingPeriod: service_domain.BillingPeriod(req.BillingPeriod),
+		Currency: strings.ToUpper(req.Currency),
 	}, nil
 }
 
 
-func transferServiceDomainToAPIModel(s *service_domain.Subscription) api_models.Subscription {
+func transferBulkCreateRequestToServiceDomain(reqs []api_models.SubscriptionCreatePostRequest) []service_domain.BulkCreateItem {
+	items := make([]service_domain.BulkCreateItem, len(reqs))
+	for i, req := range reqs {
+		subscription, err := transferCreateRequestToServiceDomain(req)
+		if err != nil {
+			items[i].Err = service_domain.InvalidInputError("INVALID_INPUT", "%s", err.Error())
+			continue
+		}
+		items[i].Subscription = subscription
+	}
+	return items
+}
+
+func transferBulkCreateResultsToAPIModel(results []service_domain.BulkCreateResult, format dateFormat) api_models.SubscriptionBulkCreatePostResponse {
+	resp := api_models.SubscriptionBulkCreatePostResponse{
+		Results: make([]api_models.SubscriptionBulkCreateResult, len(results)),
+	}
+	for i, result := range results {
+		item := api_models.SubscriptionBulkCreateResult{
+			Index: i,
+			Status: string(result.Status),
+		}
+		switch result.Status {
+		case service_domain.BulkItemCreated:
+			subscription := transferServiceDomainToAPIModel(result.Subscription, format)
+			item.Subscription = &subscription
+			resp.Created++
+		case service_domain.BulkItemFailed:
+			_, errResp := transferErrorToAPIModel(result.Err)
+			item.Error = &errResp.Error
+			resp.Failed++
+		case service_domain.BulkItemSkipped:
+			resp.Skipped++
+		}
+		resp.Results[i] = item
+	}
+	return resp
+}
+
+func transferServiceDomainToAPIModel(s *service_domain.Subscription, format dateFormat) api_models.Subscription {
 	resp := api_models.Subscription{
 		SubscriptionID: s.SubscriptionID,
 		ServiceName: s.ServiceName,
 		Price: s.Price,
 		UserID: s.UserID,
-		StartDate: transferDatetoString(s.StartDate),
+		StartDate: transferDateToFormattedString(s.StartDate, format),
+		BillingPeriod: string(s.BillingPeriod),
+		Currency: s.Currency,
+		Status: string(s.Status),
+		Version: s.Version,
+		UpdatedAt: s.UpdatedAt.Format(time.RFC3339),
 	}
 	if s.EndDate != nil {
-		str := s.EndDate.Format("01-2006")
-		resp.EndDate = str
+		resp.EndDate = transferDateToFormattedString(*s.EndDate, format)
+	}
+	if s.DeletedAt != nil {
+		resp.DeletedAt = s.DeletedAt.Format(time.RFC3339)
 	}
 
 	return resp
 }
 
-func transferServiceDomainListToAPIModelList(domainSubscriptions []service_domain.Subscription) []api_models.Subscription {
+func transferServiceDomainListToAPIModelList(domainSubscriptions []service_domain.Subscription, format dateFormat) []api_models.Subscription {
 	apiModelSubscriptionList := []api_models.Subscription{}
-	
-	for _, s := range(domainSubscriptions) {
-		apiModelSubscription := api_models.Subscription{
-			SubscriptionID: s.SubscriptionID,
-			ServiceName: s.ServiceName,
-			Price: s.Price,
-			UserID: s.UserID,
-			StartDate: transferDatetoString(s.StartDate),
+
+	for i := range domainSubscriptions {
+		apiModelSubscriptionList = append(apiModelSubscriptionList, transferServiceDomainToAPIModel(&domainSubscriptions[i], format))
+	}
+
+	return apiModelSubscriptionList
+}
+
+func transferMonthlySpendListToAPIModelList(domainSpend []service_domain.MonthlySpend, format dateFormat) []api_models.MonthlySpend {
+	apiModelSpendList := []api_models.MonthlySpend{}
+
+	for _, m := range domainSpend {
+		apiModelSpend := api_models.MonthlySpend{
+			Month:    transferDateToFormattedString(m.Month, format),
+			Total:    m.Total,
+			Services: []api_models.ServiceSpend{},
 		}
-		if s.EndDate != nil {
-			str := s.EndDate.Format("01-2006")
-			apiModelSubscription.EndDate = str
+		for _, s := range m.Services {
+			apiModelSpend.Services = append(apiModelSpend.Services, api_models.ServiceSpend{
+				ServiceName: s.ServiceName,
+				Total:       s.Total,
+			})
 		}
-		apiModelSubscriptionList = append(apiModelSubscriptionList, apiModelSubscription)
+		apiModelSpendList = append(apiModelSpendList, apiModelSpend)
 	}
 
-	return apiModelSubscriptionList
+	return apiModelSpendList
+}
+
+
+func transferPriceChangeRequestToServiceDomain(req api_models.SubscriptionPriceChangePostRequest, id uuid.UUID) (service_domain.SubscriptionPrice, error) {
+	effectiveFrom, err := transferStringMonthYearToDate(req.EffectiveFrom)
+	if err != nil {
+		return service_domain.SubscriptionPrice{}, fmt.Errorf("invalid effective_from: %w", err)
+	}
+
+	return service_domain.SubscriptionPrice{
+		SubscriptionID: id,
+		Price:          req.Price,
+		EffectiveFrom:  effectiveFrom,
+	}, nil
+}
+
+func transferPriceHistoryToAPIModel(id uuid.UUID, prices []service_domain.SubscriptionPrice) api_models.SubscriptionPriceHistoryGet200Response {
+	resp := api_models.SubscriptionPriceHistoryGet200Response{
+		SubscriptionID: id,
+		Prices:         []api_models.SubscriptionPrice{},
+	}
+
+	for _, p := range prices {
+		resp.Prices = append(resp.Prices, api_models.SubscriptionPrice{
+			Price:         p.Price,
+			EffectiveFrom: transferDatetoString(p.EffectiveFrom),
+		})
+	}
+
+	return resp
+}
+
+
+func transferRenewalListToAPIModelList(domainRenewals []service_domain.Renewal, format dateFormat) []api_models.Renewal {
+	apiModelRenewalList := []api_models.Renewal{}
+
+	for _, r := range domainRenewals {
+		apiModelRenewalList = append(apiModelRenewalList, api_models.Renewal{
+			SubscriptionID: r.SubscriptionID,
+			ServiceName:    r.ServiceName,
+			ChargeDate:     transferDateToFormattedString(r.ChargeDate, format),
+			Price:          r.Price,
+			Currency:       r.Currency,
+			BillingPeriod:  string(r.BillingPeriod),
+		})
+	}
+
+	return apiModelRenewalList
+}
+
+
+func transferPageToAPIModelPaging(query service_domain.SubscriptionListQuery, page service_domain.SubscriptionPage) api_models.ListPaging {
+	paging := api_models.ListPaging{
+		Limit:      query.Limit,
+		SortBy:     string(query.SortBy),
+		Order:      string(query.Order),
+		TotalCount: page.TotalCount,
+	}
+	if page.NextCursor != nil {
+		paging.NextCursor = service_domain.EncodeListCursor(*page.NextCursor)
+	}
+
+	return paging
+}
+
+
+func transferAuditPageToAPIModel(page service_domain.AuditPage, format dateFormat) api_models.SubscriptionHistoryGet200Response {
+	resp := api_models.SubscriptionHistoryGet200Response{
+		Entries: make([]api_models.AuditEntry, 0, len(page.Entries)),
+		NextBefore: page.NextBefore,
+	}
+	for _, entry := range page.Entries {
+		apiEntry := api_models.AuditEntry{
+			AuditID: entry.AuditID,
+			SubscriptionID: entry.SubscriptionID,
+			UserID: entry.UserID,
+			Action: string(entry.Action),
+			Actor: entry.Actor,
+			RequestID: entry.RequestID,
+			CreatedAt: entry.CreatedAt.Format(time.RFC3339),
+		}
+		if entry.Before != nil {
+			before := transferServiceDomainToAPIModel(entry.Before, format)
+			apiEntry.Before = &before
+		}
+		if entry.After != nil {
+			after := transferServiceDomainToAPIModel(entry.After, format)
+			apiEntry.After = &after
+		}
+		resp.Entries = append(resp.Entries, apiEntry)
+	}
+	return resp
 }
\ No newline at end of file
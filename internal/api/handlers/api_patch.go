@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+
+	api_models "github.com/kgugunava/effective_mobile_golang/internal/api/models"
+	service_domain "github.com/kgugunava/effective_mobile_golang/internal/domain"
+)
+
+const (
+	mergePatchContentType = "application/merge-patch+json"
+	jsonPatchContentType  = "application/json-patch+json"
+)
+
+var nullJSON = []byte("null")
+
+// transferMergePatchToServiceDomain converts a JSON Merge Patch (RFC 7396)
+// document into a subscription patch. Absent members are left untouched,
+// null removes end_date and is rejected for every other member.
+func transferMergePatchToServiceDomain(body []byte) (service_domain.SubscriptionPatch, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(body, &doc); err != nil || doc == nil {
+		return service_domain.SubscriptionPatch{}, service_domain.InvalidInputError("INVALID_PATCH", "merge patch must be a JSON object")
+	}
+
+	var patch service_domain.SubscriptionPatch
+	var errs service_domain.FieldErrors
+
+	fields := make([]string, 0, len(doc))
+	for field := range doc {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		raw := doc[field]
+		isNull := bytes.Equal(bytes.TrimSpace(raw), nullJSON)
+		if isNull && field != "end_date" {
+			if _, known := patchableFields[field]; known {
+				errs.Add(field, "must not be null")
+				continue
+			}
+		}
+
+		switch field {
+		case "service_name":
+			var v string
+			if err := json.Unmarshal(raw, &v); err != nil {
+				errs.Add(field, "must be a string")
+				continue
+			}
+			patch.ServiceName = &v
+		case "price":
+			var v int
+			if err := json.Unmarshal(raw, &v); err != nil {
+				errs.Add(field, "must be an integer")
+				continue
+			}
+			patch.Price = &v
+		case "user_id":
+			var v uuid.UUID
+			if err := json.Unmarshal(raw, &v); err != nil {
+				errs.Add(field, "must be a UUID")
+				continue
+			}
+			patch.UserID = &v
+		case "start_date", "end_date":
+			if isNull {
+				patch.ClearEndDate = true
+				continue
+			}
+			var v string
+			if err := json.Unmarshal(raw, &v); err != nil {
+				errs.Add(field, "must be a string")
+				continue
+			}
+			date, err := transferStringMonthYearToDate(v)
+			if err != nil {
+				errs.Add(field, err.Error())
+				continue
+			}
+			if field == "start_date" {
+				patch.StartDate = &date
+			} else {
+				patch.EndDate = &date
+			}
+		case "billing_period":
+			var v string
+			if err := json.Unmarshal(raw, &v); err != nil {
+				errs.Add(field, "must be a string")
+				continue
+			}
+			period := service_domain.BillingPeriod(v)
+			patch.BillingPeriod = &period
+		case "currency":
+			var v string
+			if err := json.Unmarshal(raw, &v); err != nil {
+				errs.Add(field, "must be a string")
+				continue
+			}
+			v = strings.ToUpper(v)
+			patch.Currency = &v
+		default:
+			errs.Add(field, "unknown or read-only field")
+		}
+	}
+
+	if err := errs.Err(); err != nil {
+		return service_domain.SubscriptionPatch{}, err
+	}
+	return patch, nil
+}
+
+var patchableFields = map[string]struct{}{
+	"service_name":   {},
+	"price":          {},
+	"user_id":        {},
+	"start_date":     {},
+	"end_date":       {},
+	"billing_period": {},
+	"currency":       {},
+}
+
+type jsonPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// transferJSONPatchToMergePatch applies a JSON Patch (RFC 6902) to the
+// current representation of a subscription and returns the equivalent merge
+// patch: members whose value changed, and null for removed members. Only
+// top-level members can be addressed.
+func transferJSONPatchToMergePatch(body []byte, current api_models.Subscription) ([]byte, error) {
+	var ops []jsonPatchOperation
+	if err := json.Unmarshal(body, &ops); err != nil {
+		return nil, service_domain.InvalidInputError("INVALID_PATCH", "JSON patch must be an array of operations")
+	}
+
+	original, err := toJSONMembers(current)
+	if err != nil {
+		return nil, err
+	}
+	if current.EndDate == "" {
+		delete(original, "end_date")
+	}
+
+	doc := make(map[string]json.RawMessage, len(original))
+	for k, v := range original {
+		doc[k] = v
+	}
+
+	for i, op := range ops {
+		path, err := jsonPatchMember(op.Path)
+		if err != nil {
+			return nil, service_domain.InvalidInputError("INVALID_PATCH", "operation %d: %s", i, err)
+		}
+
+		switch op.Op {
+		case "add", "replace":
+			if op.Value == nil {
+				return nil, service_domain.InvalidInputError("INVALID_PATCH", "operation %d: missing value", i)
+			}
+			if _, ok := doc[path]; !ok && op.Op == "replace" {
+				return nil, service_domain.ConflictError("PATCH_CONFLICT", "operation %d: path %s does not exist", i, op.Path)
+			}
+			doc[path] = op.Value
+		case "remove":
+			if _, ok := doc[path]; !ok {
+				return nil, service_domain.ConflictError("PATCH_CONFLICT", "operation %d: path %s does not exist", i, op.Path)
+			}
+			delete(doc, path)
+		case "move", "copy":
+			from, err := jsonPatchMember(op.From)
+			if err != nil {
+				return nil, service_domain.InvalidInputError("INVALID_PATCH", "operation %d: %s", i, err)
+			}
+			value, ok := doc[from]
+			if !ok {
+				return nil, service_domain.ConflictError("PATCH_CONFLICT", "operation %d: path %s does not exist", i, op.From)
+			}
+			if op.Op == "move" {
+				delete(doc, from)
+			}
+			doc[path] = value
+		case "test":
+			value, ok := doc[path]
+			if !ok || !jsonEqual(value, op.Value) {
+				return nil, service_domain.ConflictError("PATCH_TEST_FAILED", "operation %d: test of %s failed", i, op.Path)
+			}
+		default:
+			return nil, service_domain.InvalidInputError("INVALID_PATCH", "operation %d: unsupported op %q", i, op.Op)
+		}
+	}
+
+	mergePatch := make(map[string]json.RawMessage)
+	for k, v := range doc {
+		if old, ok := original[k]; !ok || !jsonEqual(old, v) {
+			mergePatch[k] = v
+		}
+	}
+	for k := range original {
+		if _, ok := doc[k]; !ok {
+			mergePatch[k] = nullJSON
+		}
+	}
+
+	return json.Marshal(mergePatch)
+}
+
+func jsonPatchMember(pointer string) (string, error) {
+	if !strings.HasPrefix(pointer, "/") || strings.Count(pointer, "/") != 1 || len(pointer) == 1 {
+		return "", fmt.Errorf("path %q must address a top-level member", pointer)
+	}
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(pointer[1:]), nil
+}
+
+func toJSONMembers(v interface{}) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func jsonEqual(a json.RawMessage, b json.RawMessage) bool {
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
@@ -1,15 +1,26 @@
 package handlers
 
 import (
+	"encoding/csv"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
 	api_models "github.com/kgugunava/effective_mobile_golang/internal/api/models"
+	service_domain "github.com/kgugunava/effective_mobile_golang/internal/domain"
+)
+
+const (
+	maxIdempotencyKeyLength = 255
+	maxBulkCreateItems      = 1000
+	maxImportBodySize       = 10 << 20
+	csvFlushEvery           = 100
 )
 
 type SubscriptionAPI struct {
@@ -25,6 +36,11 @@ func NewSubscriptionAPI(service SubscriptionService, logger *slog.Logger) *Subsc
 }
 
 func (api *SubscriptionAPI) SubscriptionCreatePost(c *gin.Context) {
+	format, ok := api.bindDateFormat(c)
+	if !ok {
+		return
+	}
+
 	api.logger.Info("handling create subscription request", slog.String("method", "POST"), slog.String("path", "/subscriptions"))
 
 	var newSubscription api_models.SubscriptionCreatePostRequest
@@ -34,12 +50,7 @@ func (api *SubscriptionAPI) SubscriptionCreatePost(c *gin.Context) {
 			slog.String("method", "POST"),
 			slog.Any("error", err),
 		)
-		c.JSON(500, api_models.ErrorResponse{
-			Error: api_models.ErrorResponseError{
-				Code:    "INTERNAL_ERROR",
-				Message: err.Error(),
-			},
-		})
+		api.writeError(c, bindError(err))
 		return
 	}
 
@@ -58,18 +69,26 @@ func (api *SubscriptionAPI) SubscriptionCreatePost(c *gin.Context) {
 		return
 	}
 
-	createdSubscription, err := api.subscriptionService.CreateSubscription(c.Request.Context(), transferedNewSubscription)
+	authenticated, ok := api.authenticatedUser(c)
+	if !ok {
+		return
+	}
+	defaultUserID(authenticated, &transferedNewSubscription.UserID)
+
+	idempotencyKey := c.GetHeader("Idempotency-Key")
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		api.writeError(c, service_domain.InvalidInputError("INVALID_IDEMPOTENCY_KEY",
+			"Idempotency-Key must be at most %d characters", maxIdempotencyKeyLength))
+		return
+	}
+
+	createdSubscription, err := api.subscriptionService.CreateSubscription(c.Request.Context(), transferedNewSubscription, idempotencyKey)
 	if err != nil {
 		api.logger.Error("failed to create subscription in service",
 			slog.String("method", "POST"),
 			slog.Any("error", err),
 		)
-		c.JSON(500, api_models.ErrorResponse{
-			Error: api_models.ErrorResponseError{
-				Code:    "INTERNAL_ERROR",
-				Message: err.Error(),
-			},
-		})
+		api.writeError(c, err)
 		return
 	}
 
@@ -77,10 +96,205 @@ func (api *SubscriptionAPI) SubscriptionCreatePost(c *gin.Context) {
 		slog.String("method", "POST"),
 		slog.String("subscription_id", createdSubscription.SubscriptionID.String()),
 	)
-	c.JSON(201, transferServiceDomainToAPIModel(createdSubscription))
+	setETag(c, createdSubscription.Version)
+	c.JSON(201, transferServiceDomainToAPIModel(createdSubscription, format))
+}
+
+func (api *SubscriptionAPI) SubscriptionBulkCreatePost(c *gin.Context) {
+	format, ok := api.bindDateFormat(c)
+	if !ok {
+		return
+	}
+
+	api.logger.Info("handling bulk create subscriptions request", slog.String("method", "POST"), slog.String("path", "/subscriptions/bulk"))
+
+	mode := service_domain.BulkCreateMode(c.DefaultQuery("mode", string(service_domain.BulkCreateAtomic)))
+	if !mode.IsValid() {
+		api.writeError(c, service_domain.InvalidInputError("INVALID_MODE", "mode must be one of: atomic, partial"))
+		return
+	}
+
+	var newSubscriptions []api_models.SubscriptionCreatePostRequest
+
+	if err := c.ShouldBindJSON(&newSubscriptions); err != nil {
+		api.logger.Error("failed to bind bulk create request",
+			slog.String("method", "POST"),
+			slog.Any("error", err),
+		)
+		api.writeError(c, bindError(err))
+		return
+	}
+	if len(newSubscriptions) == 0 || len(newSubscriptions) > maxBulkCreateItems {
+		api.writeError(c, service_domain.InvalidInputError("INVALID_BODY",
+			"request body must contain between 1 and %d subscriptions", maxBulkCreateItems))
+		return
+	}
+
+	authenticated, ok := api.authenticatedUser(c)
+	if !ok {
+		return
+	}
+	items := transferBulkCreateRequestToServiceDomain(newSubscriptions)
+	defaultBulkUserIDs(authenticated, items)
+
+	results, err := api.subscriptionService.CreateSubscriptions(c.Request.Context(), items, mode)
+	if err != nil {
+		api.logger.Error("failed to create subscriptions in service",
+			slog.String("method", "POST"),
+			slog.Any("error", err),
+		)
+		api.writeError(c, err)
+		return
+	}
+
+	resp := transferBulkCreateResultsToAPIModel(results, format)
+	api.logger.Info("bulk create handled",
+		slog.String("method", "POST"),
+		slog.Int("created", resp.Created),
+		slog.Int("failed", resp.Failed),
+	)
+
+	status := http.StatusMultiStatus
+	switch resp.Created {
+	case len(results):
+		status = http.StatusCreated
+	case 0:
+		status = http.StatusUnprocessableEntity
+	}
+	c.JSON(status, resp)
+}
+
+func (api *SubscriptionAPI) SubscriptionExportGet(c *gin.Context) {
+	format, ok := api.bindDateFormat(c)
+	if !ok {
+		return
+	}
+
+	api.logger.Info("handling export subscriptions request",
+		slog.String("method", "GET"),
+		slog.String("user_id", c.Query("user_id")),
+	)
+
+	userID, ok := api.bindUserID(c, c.Query("user_id"))
+	if !ok {
+		return
+	}
+	userIDStr := userID.String()
+
+	query := service_domain.SubscriptionListQuery{
+		ServiceName: c.Query("service_name"),
+		UserID:      userID,
+	}
+	if !api.bindListFilterQuery(c, &query) {
+		return
+	}
+
+	writer := csv.NewWriter(csvResponseWriter{c: c, filename: csvExportFilename(userID)})
+	if err := writer.Write(csvColumns); err != nil {
+		return
+	}
+
+	count := 0
+	err := api.subscriptionService.ExportSubscriptions(c.Request.Context(), query, func(subscription service_domain.Subscription) error {
+		if err := writer.Write(transferAPIModelToCSVRecord(transferServiceDomainToAPIModel(&subscription, format))); err != nil {
+			return err
+		}
+		count++
+		if count%csvFlushEvery == 0 {
+			writer.Flush()
+			c.Writer.Flush()
+		}
+		return writer.Error()
+	})
+	if err != nil {
+		api.logger.Error("failed to export subscriptions",
+			slog.String("method", "GET"),
+			slog.String("user_id", userIDStr),
+			slog.Any("error", err),
+		)
+		// Nothing but buffered CSV has been produced yet, so a proper
+		// error response can still replace it.
+		if !c.Writer.Written() {
+			api.writeError(c, err)
+		}
+		return
+	}
+
+	writer.Flush()
+	api.logger.Info("subscriptions exported",
+		slog.String("method", "GET"),
+		slog.String("user_id", userIDStr),
+		slog.Int("count", count),
+	)
+}
+
+func (api *SubscriptionAPI) SubscriptionImportPost(c *gin.Context) {
+	api.logger.Info("handling import subscriptions request", slog.String("method", "POST"), slog.String("path", "/subscriptions/import"))
+
+	dryRun := false
+	if dryRunStr := c.Query("dry_run"); dryRunStr != "" {
+		parsed, err := strconv.ParseBool(dryRunStr)
+		if err != nil {
+			api.writeError(c, service_domain.InvalidInputError("INVALID_DRY_RUN", "dry_run must be true or false"))
+			return
+		}
+		dryRun = parsed
+	}
+
+	rows, err := readCSVImport(http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBodySize))
+	if err != nil {
+		api.logger.Warn("failed to read import file",
+			slog.String("method", "POST"),
+			slog.Any("error", err),
+		)
+		api.writeError(c, err)
+		return
+	}
+
+	authenticated, ok := api.authenticatedUser(c)
+	if !ok {
+		return
+	}
+	items := make([]service_domain.BulkCreateItem, len(rows))
+	for i, row := range rows {
+		items[i] = row.Item
+	}
+	defaultBulkUserIDs(authenticated, items)
+
+	results, err := api.subscriptionService.ImportSubscriptions(c.Request.Context(), items, dryRun)
+	if err != nil {
+		api.logger.Error("failed to import subscriptions in service",
+			slog.String("method", "POST"),
+			slog.Any("error", err),
+		)
+		api.writeError(c, err)
+		return
+	}
+
+	resp := transferImportResultsToAPIModel(rows, results, dryRun)
+	api.logger.Info("import handled",
+		slog.String("method", "POST"),
+		slog.Bool("dry_run", dryRun),
+		slog.Int("total", resp.Total),
+		slog.Int("errors", len(resp.Errors)),
+	)
+
+	switch {
+	case len(resp.Errors) > 0:
+		c.JSON(http.StatusUnprocessableEntity, resp)
+	case dryRun:
+		c.JSON(http.StatusOK, resp)
+	default:
+		c.JSON(http.StatusCreated, resp)
+	}
 }
 
 func (api *SubscriptionAPI) SubscriptionReadGet(c *gin.Context) {
+	format, ok := api.bindDateFormat(c)
+	if !ok {
+		return
+	}
+
 	idStr := c.Param("id")
 	api.logger.Info("handling get subscription request",
 		slog.String("method", "GET"),
@@ -110,12 +324,7 @@ func (api *SubscriptionAPI) SubscriptionReadGet(c *gin.Context) {
 			slog.String("subscription_id", id.String()),
 			slog.Any("error", err),
 		)
-		c.JSON(500, api_models.ErrorResponse{
-			Error: api_models.ErrorResponseError{
-				Code:    "INTERNAL_ERROR",
-				Message: err.Error(),
-			},
-		})
+		api.writeError(c, err)
 		return
 	}
 
@@ -123,12 +332,18 @@ func (api *SubscriptionAPI) SubscriptionReadGet(c *gin.Context) {
 		slog.String("method", "GET"),
 		slog.String("subscription_id", id.String()),
 	)
+	setETag(c, subscription.Version)
 	c.JSON(200, api_models.SubscriptionReadGet200Response{
-		Subscription: transferServiceDomainToAPIModel(&subscription),
+		Subscription: transferServiceDomainToAPIModel(&subscription, format),
 	})
 }
 
 func (api *SubscriptionAPI) SubscriptionUpdatePatch(c *gin.Context) {
+	format, ok := api.bindDateFormat(c)
+	if !ok {
+		return
+	}
+
 	idStr := c.Param("id")
 	api.logger.Info("handling patch subscription request",
 		slog.String("method", "PATCH"),
@@ -151,52 +366,79 @@ func (api *SubscriptionAPI) SubscriptionUpdatePatch(c *gin.Context) {
 		return
 	}
 
-	var newSubscription api_models.SubscriptionUpdatePutRequest
-
-	if err := c.ShouldBindJSON(&newSubscription); err != nil {
-		api.logger.Error("failed to bind patch request",
+	body, err := c.GetRawData()
+	if err != nil {
+		api.logger.Error("failed to read patch request",
 			slog.String("method", "PATCH"),
 			slog.String("subscription_id", idStr),
 			slog.Any("error", err),
 		)
-		c.JSON(500, api_models.ErrorResponse{
+		api.writeError(c, bindError(err))
+		return
+	}
+
+	expectedVersions := ifMatchVersions(c)
+	switch c.ContentType() {
+	case jsonPatchContentType:
+		current, err := api.subscriptionService.GetSubscriptionByID(c.Request.Context(), id)
+		if err != nil {
+			api.logger.Error("failed to get subscription for JSON patch",
+				slog.String("method", "PATCH"),
+				slog.String("subscription_id", id.String()),
+				slog.Any("error", err),
+			)
+			api.writeError(c, err)
+			return
+		}
+		if body, err = transferJSONPatchToMergePatch(body, transferServiceDomainToAPIModel(&current, format)); err != nil {
+			api.logger.Warn("failed to apply JSON patch",
+				slog.String("method", "PATCH"),
+				slog.String("subscription_id", id.String()),
+				slog.Any("error", err),
+			)
+			api.writeError(c, err)
+			return
+		}
+		// The patch, and its test operations in particular, was applied to
+		// the subscription as read above, so the update must not go through
+		// if it has changed since.
+		if expectedVersions == nil {
+			expectedVersions = []int{current.Version}
+		}
+	case mergePatchContentType, "application/json", "":
+	default:
+		api.logger.Warn("unsupported patch content type",
+			slog.String("method", "PATCH"),
+			slog.String("content_type", c.ContentType()),
+		)
+		c.JSON(http.StatusUnsupportedMediaType, api_models.ErrorResponse{
 			Error: api_models.ErrorResponseError{
-				Code:    "INTERNAL_ERROR",
-				Message: err.Error(),
+				Code:    "UNSUPPORTED_MEDIA_TYPE",
+				Message: fmt.Sprintf("content type must be %s or %s", mergePatchContentType, jsonPatchContentType),
 			},
 		})
 		return
 	}
 
-	transferedNewSubscription, err := transferUpdatePutRequestToServiceDomain(newSubscription, id)
+	patch, err := transferMergePatchToServiceDomain(body)
 	if err != nil {
-		api.logger.Error("failed to map patch request to domain",
+		api.logger.Warn("failed to map patch request to domain",
 			slog.String("method", "PATCH"),
 			slog.String("subscription_id", id.String()),
 			slog.Any("error", err),
 		)
-		c.JSON(400, api_models.ErrorResponse{
-			Error: api_models.ErrorResponseError{
-				Code:    "INVALID_INPUT",
-				Message: err.Error(),
-			},
-		})
+		api.writeError(c, err)
 		return
 	}
 
-	updatedSubscription, err := api.subscriptionService.UpdateSubscriptionPatch(c.Request.Context(), id, &transferedNewSubscription)
+	updatedSubscription, err := api.subscriptionService.UpdateSubscriptionPatch(c.Request.Context(), id, patch, expectedVersions)
 	if err != nil {
 		api.logger.Error("failed to patch subscription",
 			slog.String("method", "PATCH"),
 			slog.String("subscription_id", id.String()),
 			slog.Any("error", err),
 		)
-		c.JSON(500, api_models.ErrorResponse{
-			Error: api_models.ErrorResponseError{
-				Code:    "INTERNAL_ERROR",
-				Message: err.Error(),
-			},
-		})
+		api.writeError(c, err)
 		return
 	}
 
@@ -204,12 +446,18 @@ func (api *SubscriptionAPI) SubscriptionUpdatePatch(c *gin.Context) {
 		slog.String("method", "PATCH"),
 		slog.String("subscription_id", id.String()),
 	)
+	setETag(c, updatedSubscription.Version)
 	c.JSON(200, api_models.SubscriptionUpdatePut200Response{
-		Subscription: transferServiceDomainToAPIModel(updatedSubscription),
+		Subscription: transferServiceDomainToAPIModel(updatedSubscription, format),
 	})
 }
 
 func (api *SubscriptionAPI) SubscriptionUpdatePut(c *gin.Context) {
+	format, ok := api.bindDateFormat(c)
+	if !ok {
+		return
+	}
+
 	idStr := c.Param("id")
 	api.logger.Info("handling put subscription request",
 		slog.String("method", "PUT"),
@@ -240,12 +488,7 @@ func (api *SubscriptionAPI) SubscriptionUpdatePut(c *gin.Context) {
 			slog.String("subscription_id", idStr),
 			slog.Any("error", err),
 		)
-		c.JSON(500, api_models.ErrorResponse{
-			Error: api_models.ErrorResponseError{
-				Code:    "INTERNAL_ERROR",
-				Message: err.Error(),
-			},
-		})
+		api.writeError(c, bindError(err))
 		return
 	}
 
@@ -265,19 +508,14 @@ func (api *SubscriptionAPI) SubscriptionUpdatePut(c *gin.Context) {
 		return
 	}
 
-	updatedSubscription, err := api.subscriptionService.UpdateSubscriptionPut(c.Request.Context(), id, &transferedNewSubscription)
+	updatedSubscription, err := api.subscriptionService.UpdateSubscriptionPut(c.Request.Context(), id, &transferedNewSubscription, ifMatchVersions(c))
 	if err != nil {
 		api.logger.Error("failed to put subscription",
 			slog.String("method", "PUT"),
 			slog.String("subscription_id", id.String()),
 			slog.Any("error", err),
 		)
-		c.JSON(500, api_models.ErrorResponse{
-			Error: api_models.ErrorResponseError{
-				Code:    "INTERNAL_ERROR",
-				Message: err.Error(),
-			},
-		})
+		api.writeError(c, err)
 		return
 	}
 
@@ -285,8 +523,9 @@ func (api *SubscriptionAPI) SubscriptionUpdatePut(c *gin.Context) {
 		slog.String("method", "PUT"),
 		slog.String("subscription_id", id.String()),
 	)
+	setETag(c, updatedSubscription.Version)
 	c.JSON(200, api_models.SubscriptionUpdatePut200Response{
-		Subscription: transferServiceDomainToAPIModel(updatedSubscription),
+		Subscription: transferServiceDomainToAPIModel(updatedSubscription, format),
 	})
 }
 
@@ -313,18 +552,13 @@ func (api *SubscriptionAPI) SubscriptionDelete(c *gin.Context) {
 		return
 	}
 
-	if err := api.subscriptionService.DeleteSubscriptionByID(c.Request.Context(), id); err != nil {
+	if err := api.subscriptionService.DeleteSubscriptionByID(c.Request.Context(), id, ifMatchVersions(c)); err != nil {
 		api.logger.Error("failed to delete subscription",
 			slog.String("method", "DELETE"),
 			slog.String("subscription_id", id.String()),
 			slog.Any("error", err),
 		)
-		c.JSON(500, api_models.ErrorResponse{
-			Error: api_models.ErrorResponseError{
-				Code:    "INTERNAL_ERROR",
-				Message: err.Error(),
-			},
-		})
+		api.writeError(c, err)
 		return
 	}
 
@@ -335,81 +569,955 @@ func (api *SubscriptionAPI) SubscriptionDelete(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
-func (api *SubscriptionAPI) SubscriptionListGet(c *gin.Context) {
-	serviceNameStr := c.Query("service_name")
-	userIDStr := c.Query("user_id")
-	startDateStr := c.Query("start_date")
-	endDateStr := c.Query("end_date")
+func (api *SubscriptionAPI) SubscriptionCancelPost(c *gin.Context) {
+	format, ok := api.bindDateFormat(c)
+	if !ok {
+		return
+	}
 
-	userID, err := uuid.Parse(userIDStr)
-	if err != nil {
-		api.logger.Warn("invalid user ID format in list request",
-			slog.String("method", "GET"),
-			slog.String("user_id", userIDStr),
-		)
-		c.JSON(400, api_models.ErrorResponse{
-			Error: api_models.ErrorResponseError{
-				Code:    "INVALID_ID",
-				Message: "invalid user ID format",
-			},
-		})
+	id, ok := api.bindLifecycleID(c, "cancel")
+	if !ok {
 		return
 	}
 
-	var startDate, endDate time.Time
+	mode := service_domain.CancelMode(c.DefaultQuery("effective", string(service_domain.CancelNow)))
+	if !mode.IsValid() {
+		api.writeError(c, service_domain.InvalidInputError("INVALID_EFFECTIVE", "effective must be one of: now, period_end"))
+		return
+	}
 
-	if startDate, err = time.Parse("01-2006", startDateStr); err != nil {
-		api.logger.Warn("invalid start date format in list request",
-			slog.String("method", "GET"),
-			slog.String("start_date", startDateStr),
+	subscription, err := api.subscriptionService.CancelSubscription(c.Request.Context(), id, mode, ifMatchVersions(c))
+	api.writeLifecycleResult(c, "cancel", id, subscription, format, err)
+}
+
+func (api *SubscriptionAPI) SubscriptionPausePost(c *gin.Context) {
+	format, ok := api.bindDateFormat(c)
+	if !ok {
+		return
+	}
+
+	id, ok := api.bindLifecycleID(c, "pause")
+	if !ok {
+		return
+	}
+
+	var pauseRequest api_models.SubscriptionPausePostRequest
+	if err := c.ShouldBindJSON(&pauseRequest); err != nil {
+		api.logger.Error("failed to bind pause request",
+			slog.String("method", "POST"),
+			slog.String("subscription_id", id.String()),
+			slog.Any("error", err),
 		)
-		c.JSON(400, api_models.ErrorResponse{
-			Error: api_models.ErrorResponseError{
-				Code:    "INVALID_START_DATE",
-				Message: "invalid start date format",
-			},
-		})
+		api.writeError(c, bindError(err))
 		return
 	}
 
-	if endDate, err = time.Parse("01-2006", endDateStr); err != nil {
-		api.logger.Warn("invalid end date format in list request",
-			slog.String("method", "GET"),
-			slog.String("end_date", endDateStr),
+	from, err := transferStringMonthYearToDate(pauseRequest.StartDate)
+	if err != nil {
+		api.writeError(c, service_domain.InvalidInputError("INVALID_START_DATE", "invalid start_date: %s", err.Error()))
+		return
+	}
+	to, err := transferStringMonthYearToDate(pauseRequest.EndDate)
+	if err != nil {
+		api.writeError(c, service_domain.InvalidInputError("INVALID_END_DATE", "invalid end_date: %s", err.Error()))
+		return
+	}
+
+	subscription, err := api.subscriptionService.PauseSubscription(c.Request.Context(), id, from, to, ifMatchVersions(c))
+	api.writeLifecycleResult(c, "pause", id, subscription, format, err)
+}
+
+func (api *SubscriptionAPI) SubscriptionResumePost(c *gin.Context) {
+	format, ok := api.bindDateFormat(c)
+	if !ok {
+		return
+	}
+
+	id, ok := api.bindLifecycleID(c, "resume")
+	if !ok {
+		return
+	}
+
+	subscription, err := api.subscriptionService.ResumeSubscription(c.Request.Context(), id, ifMatchVersions(c))
+	api.writeLifecycleResult(c, "resume", id, subscription, format, err)
+}
+
+func (api *SubscriptionAPI) bindLifecycleID(c *gin.Context, action string) (uuid.UUID, bool) {
+	idStr := c.Param("id")
+	api.logger.Info("handling "+action+" subscription request",
+		slog.String("method", "POST"),
+		slog.String("path", fmt.Sprintf("/subscriptions/%s/%s", idStr, action)),
+		slog.String("subscription_id", idStr),
+	)
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		api.logger.Warn("invalid subscription ID format in "+action,
+			slog.String("method", "POST"),
+			slog.String("subscription_id", idStr),
 		)
 		c.JSON(400, api_models.ErrorResponse{
 			Error: api_models.ErrorResponseError{
-				Code:    "INVALID_END_DATE",
-				Message: "invalid end date format",
+				Code:    "INVALID_ID",
+				Message: "invalid subscription ID format",
 			},
 		})
-		return
+		return uuid.UUID{}, false
 	}
+	return id, true
+}
 
-	domainSubscriptionsList, err := api.subscriptionService.ListSubscriptions(c.Request.Context(), serviceNameStr, userID, startDate, endDate)
-
+func (api *SubscriptionAPI) writeLifecycleResult(c *gin.Context, action string, id uuid.UUID, subscription *service_domain.Subscription, format dateFormat, err error) {
 	if err != nil {
-		api.logger.Error("failed to get subscriptions list",
-			slog.String("method", "GET"),
-			slog.String("service_name", serviceNameStr),
-			slog.String("user_id", userIDStr),
-			slog.String("start_date", startDateStr),
-			slog.String("end_date", endDateStr),
+		api.logger.Error("failed to "+action+" subscription",
+			slog.String("method", "POST"),
+			slog.String("subscription_id", id.String()),
 			slog.Any("error", err),
 		)
-		c.JSON(500, api_models.ErrorResponse{
-			Error: api_models.ErrorResponseError{
-				Code:    "INTERNAL_ERROR",
-				Message: err.Error(),
-			},
-		})
+		api.writeError(c, err)
 		return
 	}
 
-	subscriptions := transferServiceDomainListToAPIModelList(domainSubscriptionsList)
-
-	c.JSON(200, api_models.SubscriptionListGetResponse200{
-		Subscriptions: subscriptions,
+	api.logger.Info("subscription "+action+" handled successfully",
+		slog.String("method", "POST"),
+		slog.String("subscription_id", id.String()),
+		slog.String("status", string(subscription.Status)),
+	)
+	setETag(c, subscription.Version)
+	c.JSON(200, api_models.SubscriptionReadGet200Response{
+		Subscription: transferServiceDomainToAPIModel(subscription, format),
 	})
+}
+
+func (api *SubscriptionAPI) SubscriptionRestorePost(c *gin.Context) {
+	format, ok := api.bindDateFormat(c)
+	if !ok {
+		return
+	}
+
+	idStr := c.Param("id")
+	api.logger.Info("handling restore subscription request",
+		slog.String("method", "POST"),
+		slog.String("path", fmt.Sprintf("/subscriptions/%s/restore", idStr)),
+		slog.String("subscription_id", idStr),
+	)
 
-}
\ No newline at end of file
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		api.logger.Warn("invalid subscription ID format in restore",
+			slog.String("method", "POST"),
+			slog.String("subscription_id", idStr),
+		)
+		c.JSON(400, api_models.ErrorResponse{
+			Error: api_models.ErrorResponseError{
+				Code:    "INVALID_ID",
+				Message: "invalid subscription ID format",
+			},
+		})
+		return
+	}
+
+	restoredSubscription, err := api.subscriptionService.RestoreSubscription(c.Request.Context(), id)
+	if err != nil {
+		api.logger.Error("failed to restore subscription",
+			slog.String("method", "POST"),
+			slog.String("subscription_id", id.String()),
+			slog.Any("error", err),
+		)
+		api.writeError(c, err)
+		return
+	}
+
+	api.logger.Info("subscription restored successfully",
+		slog.String("method", "POST"),
+		slog.String("subscription_id", id.String()),
+	)
+	setETag(c, restoredSubscription.Version)
+	c.JSON(200, api_models.SubscriptionReadGet200Response{
+		Subscription: transferServiceDomainToAPIModel(restoredSubscription, format),
+	})
+}
+
+func (api *SubscriptionAPI) SubscriptionTrashGet(c *gin.Context) {
+	format, ok := api.bindDateFormat(c)
+	if !ok {
+		return
+	}
+
+	userIDStr := c.Query("user_id")
+
+	userID, ok := api.bindUserID(c, userIDStr)
+	if !ok {
+		return
+	}
+	userIDStr = userID.String()
+
+	query := service_domain.SubscriptionListQuery{
+		UserID:  userID,
+		Deleted: true,
+	}
+	if !api.bindPagingQuery(c, &query) {
+		return
+	}
+
+	page, err := api.subscriptionService.ListSubscriptions(c.Request.Context(), query)
+	if err != nil {
+		api.logger.Error("failed to get trash list",
+			slog.String("method", "GET"),
+			slog.String("user_id", userIDStr),
+			slog.Any("error", err),
+		)
+		api.writeError(c, err)
+		return
+	}
+
+	c.JSON(200, api_models.SubscriptionListGetResponse200{
+		Subscriptions: transferServiceDomainListToAPIModelList(page.Subscriptions, format),
+		Paging:        transferPageToAPIModelPaging(query, page),
+	})
+}
+
+func (api *SubscriptionAPI) SubscriptionHistoryGet(c *gin.Context) {
+	format, ok := api.bindDateFormat(c)
+	if !ok {
+		return
+	}
+
+	idStr := c.Param("id")
+	api.logger.Info("handling subscription history request",
+		slog.String("method", "GET"),
+		slog.String("path", fmt.Sprintf("/subscriptions/%s/history", idStr)),
+		slog.String("subscription_id", idStr),
+	)
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		api.logger.Warn("invalid subscription ID format in history request",
+			slog.String("method", "GET"),
+			slog.String("subscription_id", idStr),
+		)
+		c.JSON(400, api_models.ErrorResponse{
+			Error: api_models.ErrorResponseError{
+				Code:    "INVALID_ID",
+				Message: "invalid subscription ID format",
+			},
+		})
+		return
+	}
+
+	query := service_domain.AuditQuery{SubscriptionID: &id}
+	if !api.bindAuditPagingQuery(c, &query) {
+		return
+	}
+	api.writeAuditHistory(c, query, format)
+}
+
+func (api *SubscriptionAPI) SubscriptionUserHistoryGet(c *gin.Context) {
+	format, ok := api.bindDateFormat(c)
+	if !ok {
+		return
+	}
+
+	userID, ok := api.bindUserID(c, c.Query("user_id"))
+	if !ok {
+		return
+	}
+
+	query := service_domain.AuditQuery{UserID: &userID}
+	if !api.bindAuditPagingQuery(c, &query) {
+		return
+	}
+	api.writeAuditHistory(c, query, format)
+}
+
+func (api *SubscriptionAPI) writeAuditHistory(c *gin.Context, query service_domain.AuditQuery, format dateFormat) {
+	page, err := api.subscriptionService.GetAuditHistory(c.Request.Context(), query)
+	if err != nil {
+		api.logger.Error("failed to get audit history",
+			slog.String("method", "GET"),
+			slog.Any("error", err),
+		)
+		api.writeError(c, err)
+		return
+	}
+
+	c.JSON(200, transferAuditPageToAPIModel(page, format))
+}
+
+// bindAuditPagingQuery parses limit and before into query. It writes a 400
+// response and returns false when one of them is invalid.
+func (api *SubscriptionAPI) bindAuditPagingQuery(c *gin.Context, query *service_domain.AuditQuery) bool {
+	query.Limit = defaultListLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > maxListLimit {
+			api.writeError(c, service_domain.InvalidInputError("INVALID_LIMIT",
+				"limit must be an integer between 1 and %d", maxListLimit))
+			return false
+		}
+		query.Limit = limit
+	}
+
+	if beforeStr := c.Query("before"); beforeStr != "" {
+		before, err := strconv.ParseInt(beforeStr, 10, 64)
+		if err != nil || before < 1 {
+			api.writeError(c, service_domain.InvalidInputError("INVALID_BEFORE", "before must be a positive integer"))
+			return false
+		}
+		query.Before = &before
+	}
+
+	return true
+}
+
+func (api *SubscriptionAPI) SubscriptionListGet(c *gin.Context) {
+	format, ok := api.bindDateFormat(c)
+	if !ok {
+		return
+	}
+
+	serviceNameStr := c.Query("service_name")
+	userIDStr := c.Query("user_id")
+	startDateStr := c.Query("start_date")
+	endDateStr := c.Query("end_date")
+
+	userID, ok := api.bindUserID(c, userIDStr)
+	if !ok {
+		return
+	}
+	userIDStr = userID.String()
+
+	var startDate, endDate time.Time
+	var err error
+
+	if startDateStr != "" {
+		if startDate, err = transferStringMonthYearToDate(startDateStr); err != nil {
+			api.logger.Warn("invalid start date format in list request",
+				slog.String("method", "GET"),
+				slog.String("start_date", startDateStr),
+			)
+			c.JSON(400, api_models.ErrorResponse{
+				Error: api_models.ErrorResponseError{
+					Code:    "INVALID_START_DATE",
+					Message: "invalid start date format",
+				},
+			})
+			return
+		}
+	}
+
+	if endDateStr != "" {
+		if endDate, err = transferStringMonthYearToDate(endDateStr); err != nil {
+			api.logger.Warn("invalid end date format in list request",
+				slog.String("method", "GET"),
+				slog.String("end_date", endDateStr),
+			)
+			c.JSON(400, api_models.ErrorResponse{
+				Error: api_models.ErrorResponseError{
+					Code:    "INVALID_END_DATE",
+					Message: "invalid end date format",
+				},
+			})
+			return
+		}
+	}
+
+	query := service_domain.SubscriptionListQuery{
+		ServiceName: serviceNameStr,
+		UserID:      userID,
+		StartDate:   startDate,
+		EndDate:     endDate,
+	}
+	if !api.bindListFilterQuery(c, &query) {
+		return
+	}
+	if !api.bindPagingQuery(c, &query) {
+		return
+	}
+
+	page, err := api.subscriptionService.ListSubscriptions(c.Request.Context(), query)
+
+	if err != nil {
+		api.logger.Error("failed to get subscriptions list",
+			slog.String("method", "GET"),
+			slog.String("service_name", serviceNameStr),
+			slog.String("user_id", userIDStr),
+			slog.String("start_date", startDateStr),
+			slog.String("end_date", endDateStr),
+			slog.Any("error", err),
+		)
+		api.writeError(c, err)
+		return
+	}
+
+	subscriptions := transferServiceDomainListToAPIModelList(page.Subscriptions, format)
+
+	c.JSON(200, api_models.SubscriptionListGetResponse200{
+		Subscriptions: subscriptions,
+		Paging:        transferPageToAPIModelPaging(query, page),
+	})
+
+}
+
+// bindListFilterQuery parses the optional list filters service_name_match,
+// period_mode, active_at, min_price, max_price and has_end_date into query.
+// It writes a 400 response and returns false when a filter is invalid.
+func (api *SubscriptionAPI) bindListFilterQuery(c *gin.Context, query *service_domain.SubscriptionListQuery) bool {
+	invalidFilter := func(name string, value string, message string) bool {
+		api.logger.Warn("invalid filter in list request",
+			slog.String("method", "GET"),
+			slog.String("filter", name),
+			slog.String("value", value),
+		)
+		c.JSON(400, api_models.ErrorResponse{
+			Error: api_models.ErrorResponseError{
+				Code:    "INVALID_FILTER",
+				Message: message,
+			},
+		})
+		return false
+	}
+
+	query.ServiceNameMatch = service_domain.NameMatch(c.DefaultQuery("service_name_match", string(service_domain.NameMatchExact)))
+	if !query.ServiceNameMatch.IsValid() {
+		return invalidFilter("service_name_match", string(query.ServiceNameMatch), "service_name_match must be one of: exact, iexact, prefix, contains")
+	}
+
+	query.PeriodMode = service_domain.PeriodMode(c.DefaultQuery("period_mode", string(service_domain.PeriodModeContains)))
+	if !query.PeriodMode.IsValid() {
+		return invalidFilter("period_mode", string(query.PeriodMode), "period_mode must be one of: contains, overlaps")
+	}
+
+	if activeAtStr := c.Query("active_at"); activeAtStr != "" {
+		activeAt, err := transferStringMonthYearToDate(activeAtStr)
+		if err != nil {
+			return invalidFilter("active_at", activeAtStr, "invalid active_at format, expected MM-YYYY or YYYY-MM-DD")
+		}
+		query.ActiveAt = &activeAt
+	}
+
+	if minPriceStr := c.Query("min_price"); minPriceStr != "" {
+		minPrice, err := strconv.Atoi(minPriceStr)
+		if err != nil {
+			return invalidFilter("min_price", minPriceStr, "min_price must be an integer")
+		}
+		query.MinPrice = &minPrice
+	}
+
+	if maxPriceStr := c.Query("max_price"); maxPriceStr != "" {
+		maxPrice, err := strconv.Atoi(maxPriceStr)
+		if err != nil {
+			return invalidFilter("max_price", maxPriceStr, "max_price must be an integer")
+		}
+		query.MaxPrice = &maxPrice
+	}
+
+	if query.MinPrice != nil && query.MaxPrice != nil && *query.MinPrice > *query.MaxPrice {
+		return invalidFilter("min_price", c.Query("min_price"), "min_price must not be greater than max_price")
+	}
+
+	if hasEndDateStr := c.Query("has_end_date"); hasEndDateStr != "" {
+		hasEndDate, err := strconv.ParseBool(hasEndDateStr)
+		if err != nil {
+			return invalidFilter("has_end_date", hasEndDateStr, "has_end_date must be true or false")
+		}
+		query.HasEndDate = &hasEndDate
+	}
+
+	return true
+}
+
+// bindDateFormat parses the date_format query parameter. It writes a 400
+// response and returns false when it is invalid.
+func (api *SubscriptionAPI) bindDateFormat(c *gin.Context) (dateFormat, bool) {
+	return api.bindDateFormatDefault(c, dateFormatMonth)
+}
+
+// bindDateFormatDefault is bindDateFormat for responses whose dates are
+// rendered in format def unless the parameter says otherwise.
+func (api *SubscriptionAPI) bindDateFormatDefault(c *gin.Context, def dateFormat) (dateFormat, bool) {
+	format := dateFormat(c.DefaultQuery("date_format", string(def)))
+	if format != dateFormatMonth && format != dateFormatISO {
+		api.writeError(c, service_domain.InvalidInputError("INVALID_DATE_FORMAT", "date_format must be one of: month, iso"))
+		return "", false
+	}
+	return format, true
+}
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 500
+)
+
+// bindPagingQuery parses limit, sort_by, order and cursor into query. When a
+// cursor is given, sort_by and order default to the ones it was issued for
+// and must not contradict them. It writes a 400 response and returns false
+// when the query is invalid.
+func (api *SubscriptionAPI) bindPagingQuery(c *gin.Context, query *service_domain.SubscriptionListQuery) bool {
+	limitStr := c.Query("limit")
+	sortByStr := c.Query("sort_by")
+	orderStr := c.Query("order")
+	cursorStr := c.Query("cursor")
+
+	query.Limit = defaultListLimit
+	if limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > maxListLimit {
+			api.logger.Warn("invalid limit in list request",
+				slog.String("method", "GET"),
+				slog.String("limit", limitStr),
+			)
+			c.JSON(400, api_models.ErrorResponse{
+				Error: api_models.ErrorResponseError{
+					Code:    "INVALID_LIMIT",
+					Message: fmt.Sprintf("limit must be an integer between 1 and %d", maxListLimit),
+				},
+			})
+			return false
+		}
+		query.Limit = limit
+	}
+
+	query.SortBy = service_domain.SortByStartDate
+	query.Order = service_domain.SortOrderAsc
+	if cursorStr != "" {
+		cursor, err := service_domain.DecodeListCursor(cursorStr)
+		if err != nil {
+			api.logger.Warn("invalid cursor in list request",
+				slog.String("method", "GET"),
+				slog.String("cursor", cursorStr),
+			)
+			c.JSON(400, api_models.ErrorResponse{
+				Error: api_models.ErrorResponseError{
+					Code:    "INVALID_CURSOR",
+					Message: "invalid cursor",
+				},
+			})
+			return false
+		}
+		query.After = &cursor
+		query.SortBy = cursor.SortBy
+		query.Order = cursor.Order
+	}
+
+	if sortByStr != "" {
+		sortBy := service_domain.SortField(sortByStr)
+		if !sortBy.IsValid() || (query.After != nil && sortBy != query.SortBy) {
+			api.logger.Warn("invalid sort field in list request",
+				slog.String("method", "GET"),
+				slog.String("sort_by", sortByStr),
+			)
+			c.JSON(400, api_models.ErrorResponse{
+				Error: api_models.ErrorResponseError{
+					Code:    "INVALID_SORT",
+					Message: "sort_by must be one of: price, start_date, end_date, service_name and match the cursor",
+				},
+			})
+			return false
+		}
+		query.SortBy = sortBy
+	}
+
+	if orderStr != "" {
+		order := service_domain.SortOrder(strings.ToLower(orderStr))
+		if !order.IsValid() || (query.After != nil && order != query.Order) {
+			api.logger.Warn("invalid sort order in list request",
+				slog.String("method", "GET"),
+				slog.String("order", orderStr),
+			)
+			c.JSON(400, api_models.ErrorResponse{
+				Error: api_models.ErrorResponseError{
+					Code:    "INVALID_SORT",
+					Message: "order must be one of: asc, desc and match the cursor",
+				},
+			})
+			return false
+		}
+		query.Order = order
+	}
+
+	return true
+}
+
+func (api *SubscriptionAPI) SubscriptionTotalGet(c *gin.Context) {
+	format, ok := api.bindDateFormat(c)
+	if !ok {
+		return
+	}
+	query, ok := api.bindPeriodQuery(c, "total")
+	if !ok {
+		return
+	}
+
+	total, err := api.subscriptionService.GetTotalCost(c.Request.Context(), query)
+	if err != nil {
+		api.logger.Error("failed to get subscriptions total cost",
+			slog.String("method", "GET"),
+			slog.String("service_name", query.ServiceName),
+			slog.String("user_id", query.UserID.String()),
+			slog.Any("error", err),
+		)
+		api.writeError(c, err)
+		return
+	}
+
+	c.JSON(200, api_models.SubscriptionTotalGetResponse200{
+		TotalCost:   total,
+		UserID:      query.UserID,
+		ServiceName: query.ServiceName,
+		StartDate:   transferDateToFormattedString(query.StartDate, format),
+		EndDate:     transferDateToFormattedString(query.EndDate, format),
+		Basis:       string(query.Basis),
+		Currency:    query.Currency,
+	})
+}
+
+func (api *SubscriptionAPI) SubscriptionMonthlySpendGet(c *gin.Context) {
+	format, ok := api.bindDateFormat(c)
+	if !ok {
+		return
+	}
+	query, ok := api.bindPeriodQuery(c, "monthly spend")
+	if !ok {
+		return
+	}
+
+	monthlySpend, err := api.subscriptionService.GetMonthlySpend(c.Request.Context(), query)
+	if err != nil {
+		api.logger.Error("failed to get subscriptions monthly spend",
+			slog.String("method", "GET"),
+			slog.String("service_name", query.ServiceName),
+			slog.String("user_id", query.UserID.String()),
+			slog.Any("error", err),
+		)
+		api.writeError(c, err)
+		return
+	}
+
+	c.JSON(200, api_models.SubscriptionMonthlySpendGetResponse200{
+		UserID:      query.UserID,
+		ServiceName: query.ServiceName,
+		StartDate:   transferDateToFormattedString(query.StartDate, format),
+		EndDate:     transferDateToFormattedString(query.EndDate, format),
+		Basis:       string(query.Basis),
+		Currency:    query.Currency,
+		Months:      transferMonthlySpendListToAPIModelList(monthlySpend, format),
+	})
+}
+
+// maxCostPeriodMonths bounds the period of the cost reports, whose cost
+// grows with the number of months they cover.
+const maxCostPeriodMonths = 120
+
+// bindPeriodQuery parses user_id, service_name, basis, the target currency and
+// the start_date/end_date bounds shared by the cost report endpoints. The
+// bounds accept the same formats as the list filters and stand for the
+// months they fall in. It writes a 400 response and returns false when the
+// query is invalid.
+func (api *SubscriptionAPI) bindPeriodQuery(c *gin.Context, request string) (service_domain.CostQuery, bool) {
+	query := service_domain.CostQuery{
+		ServiceName: c.Query("service_name"),
+		Basis:       service_domain.CostBasis(c.DefaultQuery("basis", string(service_domain.CostBasisNormalized))),
+		Currency:    strings.ToUpper(c.DefaultQuery("currency", service_domain.BaseCurrency)),
+	}
+	userIDStr := c.Query("user_id")
+	startDateStr := c.Query("start_date")
+	endDateStr := c.Query("end_date")
+
+	var ok bool
+	if query.UserID, ok = api.bindUserID(c, userIDStr); !ok {
+		return service_domain.CostQuery{}, false
+	}
+
+	var err error
+
+	if query.StartDate, err = transferStringMonthYearToDate(startDateStr); err != nil {
+		api.logger.Warn(fmt.Sprintf("invalid start date format in %s request", request),
+			slog.String("method", "GET"),
+			slog.String("start_date", startDateStr),
+		)
+		c.JSON(400, api_models.ErrorResponse{
+			Error: api_models.ErrorResponseError{
+				Code:    "INVALID_START_DATE",
+				Message: "invalid start date format",
+			},
+		})
+		return service_domain.CostQuery{}, false
+	}
+
+	if query.EndDate, err = transferStringMonthYearToDate(endDateStr); err != nil {
+		api.logger.Warn(fmt.Sprintf("invalid end date format in %s request", request),
+			slog.String("method", "GET"),
+			slog.String("end_date", endDateStr),
+		)
+		c.JSON(400, api_models.ErrorResponse{
+			Error: api_models.ErrorResponseError{
+				Code:    "INVALID_END_DATE",
+				Message: "invalid end date format",
+			},
+		})
+		return service_domain.CostQuery{}, false
+	}
+
+	query.StartDate = time.Date(query.StartDate.Year(), query.StartDate.Month(), 1, 0, 0, 0, 0, time.UTC)
+	query.EndDate = time.Date(query.EndDate.Year(), query.EndDate.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	if query.EndDate.Before(query.StartDate) {
+		api.logger.Warn(fmt.Sprintf("end date before start date in %s request", request),
+			slog.String("method", "GET"),
+			slog.String("start_date", startDateStr),
+			slog.String("end_date", endDateStr),
+		)
+		c.JSON(400, api_models.ErrorResponse{
+			Error: api_models.ErrorResponseError{
+				Code:    "INVALID_PERIOD",
+				Message: "end date must not be before start date",
+			},
+		})
+		return service_domain.CostQuery{}, false
+	}
+
+	months := (query.EndDate.Year()-query.StartDate.Year())*12 + int(query.EndDate.Month()-query.StartDate.Month()) + 1
+	if months > maxCostPeriodMonths {
+		api.logger.Warn(fmt.Sprintf("period too long in %s request", request),
+			slog.String("method", "GET"),
+			slog.String("start_date", startDateStr),
+			slog.String("end_date", endDateStr),
+		)
+		c.JSON(400, api_models.ErrorResponse{
+			Error: api_models.ErrorResponseError{
+				Code:    "INVALID_PERIOD",
+				Message: fmt.Sprintf("period must span at most %d months", maxCostPeriodMonths),
+			},
+		})
+		return service_domain.CostQuery{}, false
+	}
+
+	if !query.Basis.IsValid() {
+		api.logger.Warn(fmt.Sprintf("invalid cost basis in %s request", request),
+			slog.String("method", "GET"),
+			slog.String("basis", string(query.Basis)),
+		)
+		c.JSON(400, api_models.ErrorResponse{
+			Error: api_models.ErrorResponseError{
+				Code:    "INVALID_BASIS",
+				Message: "basis must be one of: normalized, charged",
+			},
+		})
+		return service_domain.CostQuery{}, false
+	}
+
+	if !service_domain.IsKnownCurrency(query.Currency) {
+		api.logger.Warn(fmt.Sprintf("unknown currency in %s request", request),
+			slog.String("method", "GET"),
+			slog.String("currency", query.Currency),
+		)
+		c.JSON(400, api_models.ErrorResponse{
+			Error: api_models.ErrorResponseError{
+				Code:    "INVALID_CURRENCY",
+				Message: "unknown currency code",
+			},
+		})
+		return service_domain.CostQuery{}, false
+	}
+
+	return query, true
+}
+
+
+func (api *SubscriptionAPI) SubscriptionPriceChangePost(c *gin.Context) {
+	idStr := c.Param("id")
+	api.logger.Info("handling schedule price change request",
+		slog.String("method", "POST"),
+		slog.String("path", fmt.Sprintf("/subscriptions/%s/prices", idStr)),
+		slog.String("subscription_id", idStr),
+	)
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		api.logger.Warn("invalid subscription ID format in price change",
+			slog.String("method", "POST"),
+			slog.String("subscription_id", idStr),
+		)
+		c.JSON(400, api_models.ErrorResponse{
+			Error: api_models.ErrorResponseError{
+				Code:    "INVALID_ID",
+				Message: "invalid subscription ID format",
+			},
+		})
+		return
+	}
+
+	var priceChange api_models.SubscriptionPriceChangePostRequest
+
+	if err := c.ShouldBindJSON(&priceChange); err != nil {
+		api.logger.Error("failed to bind price change request",
+			slog.String("method", "POST"),
+			slog.String("subscription_id", idStr),
+			slog.Any("error", err),
+		)
+		api.writeError(c, bindError(err))
+		return
+	}
+
+	transferedPriceChange, err := transferPriceChangeRequestToServiceDomain(priceChange, id)
+	if err != nil {
+		api.logger.Error("failed to map price change request to domain",
+			slog.String("method", "POST"),
+			slog.String("subscription_id", id.String()),
+			slog.Any("error", err),
+		)
+		c.JSON(400, api_models.ErrorResponse{
+			Error: api_models.ErrorResponseError{
+				Code:    "INVALID_INPUT",
+				Message: err.Error(),
+			},
+		})
+		return
+	}
+
+	subscription, prices, err := api.subscriptionService.SchedulePriceChange(c.Request.Context(), transferedPriceChange, ifMatchVersions(c))
+	if err != nil {
+		api.logger.Error("failed to schedule price change",
+			slog.String("method", "POST"),
+			slog.String("subscription_id", id.String()),
+			slog.Any("error", err),
+		)
+		api.writeError(c, err)
+		return
+	}
+
+	api.logger.Info("price change scheduled successfully",
+		slog.String("method", "POST"),
+		slog.String("subscription_id", id.String()),
+	)
+	setETag(c, subscription.Version)
+	c.JSON(201, transferPriceHistoryToAPIModel(id, prices))
+}
+
+func (api *SubscriptionAPI) SubscriptionPriceHistoryGet(c *gin.Context) {
+	idStr := c.Param("id")
+	api.logger.Info("handling get price history request",
+		slog.String("method", "GET"),
+		slog.String("path", fmt.Sprintf("/subscriptions/%s/prices", idStr)),
+		slog.String("subscription_id", idStr),
+	)
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		api.logger.Warn("invalid subscription ID format in price history",
+			slog.String("method", "GET"),
+			slog.String("subscription_id", idStr),
+		)
+		c.JSON(400, api_models.ErrorResponse{
+			Error: api_models.ErrorResponseError{
+				Code:    "INVALID_ID",
+				Message: "invalid subscription ID format",
+			},
+		})
+		return
+	}
+
+	prices, err := api.subscriptionService.GetPriceHistory(c.Request.Context(), id)
+	if err != nil {
+		api.logger.Error("failed to get price history",
+			slog.String("method", "GET"),
+			slog.String("subscription_id", id.String()),
+			slog.Any("error", err),
+		)
+		api.writeError(c, err)
+		return
+	}
+
+	c.JSON(200, transferPriceHistoryToAPIModel(id, prices))
+}
+
+
+const (
+	maxRenewalDays   = 366
+	maxRenewalMonths = 24
+)
+
+func (api *SubscriptionAPI) SubscriptionRenewalsGet(c *gin.Context) {
+	// Charges fall on a specific day, so the dates are ISO dates unless the
+	// caller asks for months.
+	format, ok := api.bindDateFormatDefault(c, dateFormatISO)
+	if !ok {
+		return
+	}
+
+	userIDStr := c.Query("user_id")
+	daysStr := c.Query("days")
+	monthsStr := c.Query("months")
+
+	userID, ok := api.bindUserID(c, userIDStr)
+	if !ok {
+		return
+	}
+	userIDStr = userID.String()
+
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 30)
+
+	switch {
+	case daysStr != "" && monthsStr != "":
+		c.JSON(400, api_models.ErrorResponse{
+			Error: api_models.ErrorResponseError{
+				Code:    "INVALID_HORIZON",
+				Message: "only one of days or months can be specified",
+			},
+		})
+		return
+	case daysStr != "":
+		days, err := strconv.Atoi(daysStr)
+		if err != nil || days < 1 || days > maxRenewalDays {
+			api.logger.Warn("invalid days in renewals request",
+				slog.String("method", "GET"),
+				slog.String("days", daysStr),
+			)
+			c.JSON(400, api_models.ErrorResponse{
+				Error: api_models.ErrorResponseError{
+					Code:    "INVALID_HORIZON",
+					Message: fmt.Sprintf("days must be an integer between 1 and %d", maxRenewalDays),
+				},
+			})
+			return
+		}
+		to = from.AddDate(0, 0, days)
+	case monthsStr != "":
+		months, err := strconv.Atoi(monthsStr)
+		if err != nil || months < 1 || months > maxRenewalMonths {
+			api.logger.Warn("invalid months in renewals request",
+				slog.String("method", "GET"),
+				slog.String("months", monthsStr),
+			)
+			c.JSON(400, api_models.ErrorResponse{
+				Error: api_models.ErrorResponseError{
+					Code:    "INVALID_HORIZON",
+					Message: fmt.Sprintf("months must be an integer between 1 and %d", maxRenewalMonths),
+				},
+			})
+			return
+		}
+		to = from.AddDate(0, months, 0)
+	}
+
+	renewals, err := api.subscriptionService.GetUpcomingRenewals(c.Request.Context(), userID, from, to)
+	if err != nil {
+		api.logger.Error("failed to get upcoming renewals",
+			slog.String("method", "GET"),
+			slog.String("user_id", userIDStr),
+			slog.Any("error", err),
+		)
+		api.writeError(c, err)
+		return
+	}
+
+	c.JSON(200, api_models.SubscriptionRenewalsGet200Response{
+		UserID:   userID,
+		From:     transferDateToFormattedString(from, format),
+		To:       transferDateToFormattedString(to, format),
+		Renewals: transferRenewalListToAPIModelList(renewals, format),
+	})
+}
@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	api_models "github.com/kgugunava/effective_mobile_golang/internal/api/models"
+	"github.com/kgugunava/effective_mobile_golang/internal/auth"
+	"github.com/kgugunava/effective_mobile_golang/internal/domain"
+)
+
+// fakeSubscriptionService records the queries it is called with. Methods a
+// test does not override panic through the nil embedded interface.
+type fakeSubscriptionService struct {
+	SubscriptionService
+	costQueries  []domain.CostQuery
+	subscription domain.Subscription
+	patches      []fakePatchCall
+}
+
+type fakePatchCall struct {
+	patch            domain.SubscriptionPatch
+	expectedVersions []int
+}
+
+func (s *fakeSubscriptionService) GetTotalCost(ctx context.Context, query domain.CostQuery) (int64, error) {
+	s.costQueries = append(s.costQueries, query)
+	return 1200, nil
+}
+
+func (s *fakeSubscriptionService) GetSubscriptionByID(ctx context.Context, id uuid.UUID) (domain.Subscription, error) {
+	return s.subscription, nil
+}
+
+func (s *fakeSubscriptionService) UpdateSubscriptionPatch(ctx context.Context, id uuid.UUID, patch domain.SubscriptionPatch, expectedVersions []int) (*domain.Subscription, error) {
+	s.patches = append(s.patches, fakePatchCall{patch: patch, expectedVersions: expectedVersions})
+	updated := patch.Apply(s.subscription)
+	updated.Version++
+	return &updated, nil
+}
+
+// serveAs serves a single request to handler on behalf of principal.
+func serveAs(principal domain.Principal, pattern string, handler func(api *SubscriptionAPI) gin.HandlerFunc, service SubscriptionService, target string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	api := NewSubscriptionAPI(service, slog.New(slog.DiscardHandler))
+
+	router := gin.New()
+	router.GET(pattern, func(c *gin.Context) {
+		auth.SetPrincipal(c, principal)
+	}, handler(api))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	return w
+}
+
+func TestSubscriptionTotalGet(t *testing.T) {
+	userID := uuid.New()
+	principal := domain.Principal{UserID: userID, Role: domain.RoleUser}
+
+	tests := []struct {
+		name      string
+		query     string
+		wantCode  string
+		wantQuery domain.CostQuery
+		wantStart string
+		wantEnd   string
+	}{
+		{
+			name:  "month bounds",
+			query: "start_date=01-2025&end_date=03-2025&service_name=Netflix",
+			wantQuery: domain.CostQuery{
+				UserID:      userID,
+				ServiceName: "Netflix",
+				StartDate:   time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+				EndDate:     time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC),
+				Basis:       domain.CostBasisNormalized,
+				Currency:    domain.BaseCurrency,
+			},
+			wantStart: "01-2025",
+			wantEnd:   "03-2025",
+		},
+		{
+			name:  "ISO dates in the response",
+			query: "start_date=01-2025&end_date=03-2025&date_format=iso",
+			wantQuery: domain.CostQuery{
+				UserID:    userID,
+				StartDate: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+				EndDate:   time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC),
+				Basis:     domain.CostBasisNormalized,
+				Currency:  domain.BaseCurrency,
+			},
+			wantStart: "2025-01-01",
+			wantEnd:   "2025-03-01",
+		},
+		{
+			name:     "unknown date format",
+			query:    "start_date=01-2025&end_date=03-2025&date_format=unix",
+			wantCode: "INVALID_DATE_FORMAT",
+		},
+		{
+			name:  "day bounds stand for their months",
+			query: "start_date=2025-01-15&end_date=2025-03-31&basis=charged&currency=usd",
+			wantQuery: domain.CostQuery{
+				UserID:    userID,
+				StartDate: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+				EndDate:   time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC),
+				Basis:     domain.CostBasisCharged,
+				Currency:  "USD",
+			},
+		},
+		{
+			name:     "malformed start date",
+			query:    "start_date=2025/01&end_date=03-2025",
+			wantCode: "INVALID_START_DATE",
+		},
+		{
+			name:     "missing end date",
+			query:    "start_date=01-2025",
+			wantCode: "INVALID_END_DATE",
+		},
+		{
+			name:     "end before start",
+			query:    "start_date=03-2025&end_date=01-2025",
+			wantCode: "INVALID_PERIOD",
+		},
+		{
+			name:     "period longer than the maximum",
+			query:    "start_date=01-2015&end_date=01-2025",
+			wantCode: "INVALID_PERIOD",
+		},
+		{
+			name:     "unknown basis",
+			query:    "start_date=01-2025&end_date=03-2025&basis=yearly",
+			wantCode: "INVALID_BASIS",
+		},
+		{
+			name:     "unknown currency",
+			query:    "start_date=01-2025&end_date=03-2025&currency=XYZ",
+			wantCode: "INVALID_CURRENCY",
+		},
+		{
+			name:     "malformed user ID",
+			query:    "start_date=01-2025&end_date=03-2025&user_id=42",
+			wantCode: "INVALID_ID",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &fakeSubscriptionService{}
+			w := serveAs(principal, "/subscriptions/total", func(api *SubscriptionAPI) gin.HandlerFunc {
+				return api.SubscriptionTotalGet
+			}, service, "/subscriptions/total?"+tt.query)
+
+			if tt.wantCode != "" {
+				if w.Code != http.StatusBadRequest {
+					t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusBadRequest, w.Body)
+				}
+				var resp api_models.ErrorResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("decode error response: %v", err)
+				}
+				if resp.Error.Code != tt.wantCode {
+					t.Errorf("error code = %q, want %q", resp.Error.Code, tt.wantCode)
+				}
+				if len(service.costQueries) != 0 {
+					t.Errorf("service called with %+v, want no call", service.costQueries)
+				}
+				return
+			}
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+			}
+			if len(service.costQueries) != 1 {
+				t.Fatalf("service called %d times, want 1", len(service.costQueries))
+			}
+			if got := service.costQueries[0]; got != tt.wantQuery {
+				t.Errorf("query = %+v, want %+v", got, tt.wantQuery)
+			}
+
+			var resp api_models.SubscriptionTotalGetResponse200
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if resp.TotalCost != 1200 {
+				t.Errorf("total_cost = %d, want 1200", resp.TotalCost)
+			}
+			if tt.wantStart != "" && (resp.StartDate != tt.wantStart || resp.EndDate != tt.wantEnd) {
+				t.Errorf("period = %s..%s, want %s..%s", resp.StartDate, resp.EndDate, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestSubscriptionUpdatePatchPinsVersion(t *testing.T) {
+	userID := uuid.New()
+	principal := domain.Principal{UserID: userID, Role: domain.RoleUser}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		ifMatch     string
+		want        []int
+	}{
+		{name: "JSON patch without If-Match", contentType: "application/json-patch+json", body: `[{"op":"test","path":"/price","value":400},{"op":"replace","path":"/price","value":500}]`, want: []int{3}},
+		{name: "JSON patch with If-Match", contentType: "application/json-patch+json", body: `[{"op":"replace","path":"/price","value":500}]`, ifMatch: `"2"`, want: []int{2}},
+		{name: "merge patch without If-Match", contentType: "application/merge-patch+json", body: `{"price":500}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &fakeSubscriptionService{subscription: domain.Subscription{
+				SubscriptionID: uuid.New(),
+				ServiceName:    "Netflix",
+				Price:          400,
+				UserID:         userID,
+				StartDate:      time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+				BillingPeriod:  domain.BillingPeriodMonthly,
+				Currency:       domain.BaseCurrency,
+				Status:         domain.StatusActive,
+				Version:        3,
+			}}
+			api := NewSubscriptionAPI(service, slog.New(slog.DiscardHandler))
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.PATCH("/subscriptions/:id", func(c *gin.Context) {
+				auth.SetPrincipal(c, principal)
+			}, api.SubscriptionUpdatePatch)
+
+			req := httptest.NewRequest(http.MethodPatch, "/subscriptions/"+service.subscription.SubscriptionID.String(), strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+			}
+			if len(service.patches) != 1 {
+				t.Fatalf("service patched %d times, want 1", len(service.patches))
+			}
+			if got := service.patches[0].expectedVersions; !slices.Equal(got, tt.want) {
+				t.Errorf("expected versions = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -10,10 +10,25 @@ import (
 )
 
 type SubscriptionService interface {
-	CreateSubscription(ctx context.Context, subscription *domain.Subscription) (*domain.Subscription, error)
+	CreateSubscription(ctx context.Context, subscription *domain.Subscription, idempotencyKey string) (*domain.Subscription, error)
+	CreateSubscriptions(ctx context.Context, items []domain.BulkCreateItem, mode domain.BulkCreateMode) ([]domain.BulkCreateResult, error)
 	GetSubscriptionByID(ctx context.Context, id uuid.UUID) (domain.Subscription, error)
-	UpdateSubscriptionPut(ctx context.Context, id uuid.UUID, newSubscription *domain.Subscription) (*domain.Subscription, error)
-	UpdateSubscriptionPatch(ctx context.Context, id uuid.UUID, newSubscription *domain.Subscription) (*domain.Subscription, error) 
-	DeleteSubscriptionByID(ctx context.Context, id uuid.UUID) error
-	ListSubscriptions(ctx context.Context, ServiceName string, UserID uuid.UUID, StartDate time.Time, EndDate time.Time) ([]domain.Subscription, error) 
+	UpdateSubscriptionPut(ctx context.Context, id uuid.UUID, newSubscription *domain.Subscription, expectedVersions []int) (*domain.Subscription, error)
+	UpdateSubscriptionPatch(ctx context.Context, id uuid.UUID, patch domain.SubscriptionPatch, expectedVersions []int) (*domain.Subscription, error)
+	DeleteSubscriptionByID(ctx context.Context, id uuid.UUID, expectedVersions []int) error
+	CancelSubscription(ctx context.Context, id uuid.UUID, mode domain.CancelMode, expectedVersions []int) (*domain.Subscription, error)
+	PauseSubscription(ctx context.Context, id uuid.UUID, from time.Time, to time.Time, expectedVersions []int) (*domain.Subscription, error)
+	ResumeSubscription(ctx context.Context, id uuid.UUID, expectedVersions []int) (*domain.Subscription, error)
+	RestoreSubscription(ctx context.Context, id uuid.UUID) (*domain.Subscription, error)
+	GetAuditHistory(ctx context.Context, query domain.AuditQuery) (domain.AuditPage, error)
+	GetAllAuditHistory(ctx context.Context, query domain.AuditQuery) (domain.AuditPage, error)
+	ListSubscriptions(ctx context.Context, query domain.SubscriptionListQuery) (domain.SubscriptionPage, error)
+	ListAllSubscriptions(ctx context.Context, query domain.SubscriptionListQuery) (domain.SubscriptionPage, error)
+	ExportSubscriptions(ctx context.Context, query domain.SubscriptionListQuery, fn func(domain.Subscription) error) error
+	ImportSubscriptions(ctx context.Context, items []domain.BulkCreateItem, dryRun bool) ([]domain.BulkCreateResult, error)
+	GetTotalCost(ctx context.Context, query domain.CostQuery) (int64, error)
+	SchedulePriceChange(ctx context.Context, price domain.SubscriptionPrice, expectedVersions []int) (domain.Subscription, []domain.SubscriptionPrice, error)
+	GetPriceHistory(ctx context.Context, id uuid.UUID) ([]domain.SubscriptionPrice, error)
+	GetUpcomingRenewals(ctx context.Context, userID uuid.UUID, from time.Time, to time.Time) ([]domain.Renewal, error)
+	GetMonthlySpend(ctx context.Context, query domain.CostQuery) ([]domain.MonthlySpend, error)
 }
\ No newline at end of file
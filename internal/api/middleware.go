@@ -0,0 +1,63 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/kgugunava/effective_mobile_golang/internal/requestctx"
+)
+
+const (
+	requestIDHeader = "X-Request-ID"
+	actorHeader     = "X-Actor"
+	maxRequestIDLen = 128
+)
+
+// RequestContext stores the request ID and the actor of every request in its
+// context. The request ID is taken from X-Request-ID when the client sends a
+// usable one and generated otherwise; it is echoed in the response.
+func RequestContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(requestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLen {
+			requestID = uuid.NewString()
+		}
+		c.Header(requestIDHeader, requestID)
+
+		ctx := requestctx.WithRequestID(c.Request.Context(), requestID)
+		if actor := c.GetHeader(actorHeader); actor != "" {
+			ctx = requestctx.WithActor(ctx, actor)
+		}
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// Deprecated marks the responses of a route that is going away with the
+// Deprecation (RFC 9745) and Sunset (RFC 8594) headers and links the route
+// that replaces it. Path parameters in successor are filled in from the
+// request.
+func Deprecated(successor string, deprecation time.Time, sunset time.Time) gin.HandlerFunc {
+	deprecationValue := fmt.Sprintf("@%d", deprecation.Unix())
+	sunsetValue := sunset.UTC().Format(http.TimeFormat)
+
+	return func(c *gin.Context) {
+		link := successor
+		for _, param := range c.Params {
+			link = strings.ReplaceAll(link, ":"+param.Key, url.PathEscape(param.Value))
+		}
+
+		c.Header("Deprecation", deprecationValue)
+		c.Header("Sunset", sunsetValue)
+		c.Header("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, link))
+
+		c.Next()
+	}
+}
@@ -0,0 +1,8 @@
+package models
+
+type SubscriptionBulkCreatePostResponse struct {
+	Created int `json:"created"`
+	Failed int `json:"failed"`
+	Skipped int `json:"skipped"`
+	Results []SubscriptionBulkCreateResult `json:"results"`
+}
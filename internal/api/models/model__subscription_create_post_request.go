@@ -10,4 +10,6 @@ type SubscriptionCreatePostRequest struct {
 	UserID uuid.UUID `json:"user_id"`
 	StartDate string `json:"start_date"`
 	EndDate string `json:"end_date"`
+	BillingPeriod string `json:"billing_period"`
+	Currency string `json:"currency"`
 }
\ No newline at end of file
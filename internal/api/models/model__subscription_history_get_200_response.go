@@ -0,0 +1,7 @@
+package models
+
+type SubscriptionHistoryGet200Response struct {
+	Entries []AuditEntry `json:"entries"`
+	// NextBefore is passed as before to fetch the next, older page.
+	NextBefore *int64 `json:"next_before,omitempty"`
+}
@@ -0,0 +1,9 @@
+package models
+
+type SubscriptionImportPostResponse struct {
+	DryRun bool `json:"dry_run"`
+	Total int `json:"total"`
+	Created int `json:"created"`
+	Valid int `json:"valid"`
+	Errors []SubscriptionImportError `json:"errors"`
+}
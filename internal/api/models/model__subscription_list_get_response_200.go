@@ -2,4 +2,5 @@ package models
 
 type SubscriptionListGetResponse200 struct {
 	Subscriptions []Subscription `json:"subscriptions"`
+	Paging ListPaging `json:"paging"`
 }
\ No newline at end of file
@@ -0,0 +1,15 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+type SubscriptionMonthlySpendGetResponse200 struct {
+	UserID uuid.UUID `json:"user_id"`
+	ServiceName string `json:"service_name,omitempty"`
+	StartDate string `json:"start_date"`
+	EndDate string `json:"end_date"`
+	Basis string `json:"basis"`
+	Currency string `json:"currency"`
+	Months []MonthlySpend `json:"months"`
+}
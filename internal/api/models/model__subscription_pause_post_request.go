@@ -0,0 +1,6 @@
+package models
+
+type SubscriptionPausePostRequest struct {
+	StartDate string `json:"start_date"`
+	EndDate string `json:"end_date"`
+}
@@ -0,0 +1,6 @@
+package models
+
+type SubscriptionPriceChangePostRequest struct {
+	Price int `json:"price"`
+	EffectiveFrom string `json:"effective_from"`
+}
@@ -0,0 +1,10 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+type SubscriptionPriceHistoryGet200Response struct {
+	SubscriptionID uuid.UUID `json:"subscription_id"`
+	Prices []SubscriptionPrice `json:"prices"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+type SubscriptionRenewalsGet200Response struct {
+	UserID uuid.UUID `json:"user_id"`
+	From string `json:"from"`
+	To string `json:"to"`
+	Renewals []Renewal `json:"renewals"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+type SubscriptionTotalGetResponse200 struct {
+	TotalCost int64 `json:"total_cost"`
+	UserID uuid.UUID `json:"user_id"`
+	ServiceName string `json:"service_name,omitempty"`
+	StartDate string `json:"start_date"`
+	EndDate string `json:"end_date"`
+	Basis string `json:"basis"`
+	Currency string `json:"currency"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+type AuditEntry struct {
+	AuditID int64 `json:"audit_id"`
+	SubscriptionID uuid.UUID `json:"subscription_id"`
+	UserID uuid.UUID `json:"user_id"`
+	Action string `json:"action"`
+	Actor string `json:"actor"`
+	RequestID string `json:"request_id"`
+	Before *Subscription `json:"before"`
+	After *Subscription `json:"after"`
+	CreatedAt string `json:"created_at"`
+}
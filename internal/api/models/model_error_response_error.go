@@ -3,4 +3,5 @@ package models
 type ErrorResponseError struct {
 	Code string `json:"code"`
 	Message string `json:"message"`
+	Details []ErrorResponseErrorDetail `json:"details,omitempty"`
 }
\ No newline at end of file
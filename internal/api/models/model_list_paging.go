@@ -0,0 +1,9 @@
+package models
+
+type ListPaging struct {
+	Limit int `json:"limit"`
+	SortBy string `json:"sort_by"`
+	Order string `json:"order"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	TotalCount int64 `json:"total_count"`
+}
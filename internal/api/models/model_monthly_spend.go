@@ -0,0 +1,7 @@
+package models
+
+type MonthlySpend struct {
+	Month string `json:"month"`
+	Total int64 `json:"total"`
+	Services []ServiceSpend `json:"services"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+type Renewal struct {
+	SubscriptionID uuid.UUID `json:"subscription_id"`
+	ServiceName string `json:"service_name"`
+	ChargeDate string `json:"charge_date"`
+	Price int `json:"price"`
+	Currency string `json:"currency"`
+	BillingPeriod string `json:"billing_period"`
+}
@@ -0,0 +1,6 @@
+package models
+
+type ServiceSpend struct {
+	ServiceName string `json:"service_name"`
+	Total int64 `json:"total"`
+}
@@ -12,4 +12,10 @@ type Subscription struct {
 	UserID uuid.UUID `json:"user_id"`
 	StartDate string `json:"start_date"`
 	EndDate string `json:"end_date"`
+	BillingPeriod string `json:"billing_period"`
+	Currency string `json:"currency"`
+	Status string `json:"status"`
+	Version int `json:"version"`
+	UpdatedAt string `json:"updated_at"`
+	DeletedAt string `json:"deleted_at,omitempty"`
 }
\ No newline at end of file
@@ -0,0 +1,8 @@
+package models
+
+type SubscriptionBulkCreateResult struct {
+	Index int `json:"index"`
+	Status string `json:"status"`
+	Subscription *Subscription `json:"subscription,omitempty"`
+	Error *ErrorResponseError `json:"error,omitempty"`
+}
@@ -0,0 +1,6 @@
+package models
+
+type SubscriptionImportError struct {
+	Line int `json:"line"`
+	Error ErrorResponseError `json:"error"`
+}
@@ -0,0 +1,119 @@
+// Package openapi embeds the OpenAPI document of the v1 API and the Swagger
+// UI page rendering it, along with the Swagger UI assets the page loads so
+// that the documentation works without access to a CDN.
+package openapi
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Spec is the OpenAPI 3 document describing the v1 API. Its paths are
+// relative to the /api/v1 server URL.
+//
+//go:embed openapi.json
+var Spec []byte
+
+// SwaggerUI is the page rendering Spec.
+//
+//go:embed swagger.html
+var SwaggerUI []byte
+
+//go:embed swagger-ui
+var swaggerUIDist embed.FS
+
+// SwaggerUIAssets holds the script and stylesheet of swagger-ui 5.18.2
+// loaded by SwaggerUI.
+var SwaggerUIAssets = mustSub(swaggerUIDist, "swagger-ui")
+
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+// Operation is an operation declared by Spec.
+type Operation struct {
+	Method      string
+	Path        string
+	OperationID string
+}
+
+// Operations lists the operations declared by Spec, sorted by path and
+// method.
+func Operations() ([]Operation, error) {
+	var doc struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(Spec, &doc); err != nil {
+		return nil, fmt.Errorf("parse openapi document: %w", err)
+	}
+
+	var operations []Operation
+	for path, item := range doc.Paths {
+		for method, raw := range item {
+			if !isHTTPMethod(method) {
+				continue
+			}
+			var op struct {
+				OperationID string `json:"operationId"`
+			}
+			if err := json.Unmarshal(raw, &op); err != nil {
+				return nil, fmt.Errorf("parse %s %s: %w", method, path, err)
+			}
+			operations = append(operations, Operation{
+				Method:      strings.ToUpper(method),
+				Path:        path,
+				OperationID: op.OperationID,
+			})
+		}
+	}
+
+	sort.Slice(operations, func(i, j int) bool {
+		if operations[i].Path != operations[j].Path {
+			return operations[i].Path < operations[j].Path
+		}
+		return operations[i].Method < operations[j].Method
+	})
+	return operations, nil
+}
+
+func isHTTPMethod(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+var ginParam = regexp.MustCompile(`:([A-Za-z_][A-Za-z0-9_]*)`)
+
+// PathFromPattern converts a gin route pattern into an OpenAPI path, e.g.
+// /subscriptions/:id into /subscriptions/{id}.
+func PathFromPattern(pattern string) string {
+	return ginParam.ReplaceAllString(pattern, "{$1}")
+}
+
+// Load parses and validates Spec.
+func Load(ctx context.Context) (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(Spec)
+	if err != nil {
+		return nil, fmt.Errorf("load openapi document: %w", err)
+	}
+	if err := doc.Validate(ctx); err != nil {
+		return nil, fmt.Errorf("validate openapi document: %w", err)
+	}
+	return doc, nil
+}
@@ -1,11 +1,16 @@
 package api
 
 import (
+	"context"
+	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/kgugunava/effective_mobile_golang/internal/api/handlers"
+	"github.com/kgugunava/effective_mobile_golang/internal/api/openapi"
+	"github.com/kgugunava/effective_mobile_golang/internal/auth"
 )
 
 type Route struct {
@@ -19,73 +24,290 @@ type Route struct {
 	HandlerFunc	gin.HandlerFunc
 }
 
-func NewRouter(apiHandler handlers.SubscriptionAPI) *gin.Engine {
-	return NewRouterWithGinEngine(gin.Default(), apiHandler)
+func NewRouter(apiHandler handlers.SubscriptionAPI, verifier *auth.JWTVerifier, apiKeys APIKeyVerifier, logger *slog.Logger) *gin.Engine {
+	return NewRouterWithGinEngine(gin.Default(), apiHandler, verifier, apiKeys, logger)
 }
 
-func NewRouterWithGinEngine(router *gin.Engine, apiHandler handlers.SubscriptionAPI) *gin.Engine {
-	for _, route := range getRoutes(apiHandler) {
-		if route.HandlerFunc == nil {
-			route.HandlerFunc = DefaultHandleFunc
+// apiV1Prefix is where the current version of the API is served.
+const apiV1Prefix = "/api/v1"
+
+// LegacyRoute is an RPC-style path kept as a deprecated alias of the v1
+// route at Successor.
+type LegacyRoute struct {
+	Route
+	// Successor is the v1 pattern that replaces the route.
+	Successor string
+}
+
+var (
+	// legacyDeprecation is when the legacy routes were deprecated.
+	legacyDeprecation = time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC)
+	// legacySunset is when the legacy routes are going to be removed.
+	legacySunset = time.Date(2027, time.April, 17, 0, 0, 0, 0, time.UTC)
+)
+
+func NewRouterWithGinEngine(router *gin.Engine, apiHandler handlers.SubscriptionAPI, verifier *auth.JWTVerifier, apiKeys APIKeyVerifier, logger *slog.Logger) *gin.Engine {
+	routes := getRoutes(apiHandler)
+	legacyRoutes := getLegacyRoutes(apiHandler)
+	if err := checkRoutesDocumented(routes); err != nil {
+		panic(err)
+	}
+
+	validator, err := newRouteValidator(routes, legacyRoutes)
+	if err != nil {
+		panic(err)
+	}
+
+	router.Use(RequestContext())
+
+	// The API routes require authentication, the documentation is public.
+	apiMiddleware := []gin.HandlerFunc{Authenticate(verifier, apiKeys, logger), ValidateRequest(validator)}
+
+	v1 := router.Group(apiV1Prefix, apiMiddleware...)
+	for _, route := range routes {
+		registerRoute(v1, route)
+	}
+
+	router.GET("/openapi.json", OpenAPISpec)
+	router.GET("/docs", SwaggerUI)
+	for _, asset := range []string{"swagger-ui.css", "swagger-ui-bundle.js"} {
+		router.StaticFileFS("/docs/assets/"+asset, asset, http.FS(openapi.SwaggerUIAssets))
+	}
+
+	for _, route := range legacyRoutes {
+		middleware := append([]gin.HandlerFunc{Deprecated(apiV1Prefix+route.Successor, legacyDeprecation, legacySunset)}, apiMiddleware...)
+		registerRoute(router, route.Route, middleware...)
+	}
+
+	return router
+}
+
+// newRouteValidator validates requests to the v1 routes and their legacy
+// aliases against the embedded OpenAPI document.
+func newRouteValidator(routes []Route, legacyRoutes []LegacyRoute) (*RequestValidator, error) {
+	doc, err := openapi.Load(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	validator := NewRequestValidator(doc)
+	bySuccessor := make(map[string]Route, len(routes))
+	for _, route := range routes {
+		if err := validator.Add(apiV1Prefix+route.Pattern, route); err != nil {
+			return nil, err
 		}
-		switch route.Method {
-		case http.MethodGet:
-			router.GET(route.Pattern, route.HandlerFunc)
-		case http.MethodPost:
-			router.POST(route.Pattern, route.HandlerFunc)
-		case http.MethodPut:
-			router.PUT(route.Pattern, route.HandlerFunc)
-		case http.MethodPatch:
-			router.PATCH(route.Pattern, route.HandlerFunc)
-		case http.MethodDelete:
-			router.DELETE(route.Pattern, route.HandlerFunc)
+		bySuccessor[route.Method+" "+route.Pattern] = route
+	}
+	for _, route := range legacyRoutes {
+		if err := validator.Add(route.Pattern, bySuccessor[route.Method+" "+route.Successor]); err != nil {
+			return nil, err
 		}
 	}
+	return validator, nil
+}
 
-	return router
+func registerRoute(router gin.IRoutes, route Route, middleware ...gin.HandlerFunc) {
+	if route.HandlerFunc == nil {
+		route.HandlerFunc = DefaultHandleFunc
+	}
+	router.Handle(route.Method, route.Pattern, append(middleware, route.HandlerFunc)...)
 }
 
 func DefaultHandleFunc(c *gin.Context) {
 	c.String(http.StatusNotImplemented, "501 not implemented")
 }
 
+// getRoutes returns the routes of the v1 API, relative to apiV1Prefix.
 func getRoutes(apiHandler handlers.SubscriptionAPI) []Route {
-	return []Route{ 
+	return []Route{
 		{
 			"SubscriptionCreatePost",
 			http.MethodPost,
-			"/create",
+			"/subscriptions",
 			apiHandler.SubscriptionCreatePost,
 		},
+		{
+			"SubscriptionsListGet",
+			http.MethodGet,
+			"/subscriptions",
+			apiHandler.SubscriptionListGet,
+		},
 		{
 			"SubscriptionReadGet",
 			http.MethodGet,
-			"/read/:id",
+			"/subscriptions/:id",
 			apiHandler.SubscriptionReadGet,
 		},
 		{
 			"SubscriptionUpdatePut",
 			http.MethodPut,
-			"/update_put/:id",
+			"/subscriptions/:id",
 			apiHandler.SubscriptionUpdatePut,
 		},
 		{
 			"SubscriptionUpdatePatch",
 			http.MethodPatch,
-			"/update_patch/:id",
+			"/subscriptions/:id",
 			apiHandler.SubscriptionUpdatePatch,
 		},
 		{
 			"SubscriptionDelete",
 			http.MethodDelete,
-			"/delete/:id",
+			"/subscriptions/:id",
 			apiHandler.SubscriptionDelete,
 		},
 		{
-			"SubscriptionsListGet",
+			"SubscriptionBulkCreatePost",
+			http.MethodPost,
+			"/subscriptions/bulk",
+			apiHandler.SubscriptionBulkCreatePost,
+		},
+		{
+			"SubscriptionImportPost",
+			http.MethodPost,
+			"/subscriptions/import",
+			apiHandler.SubscriptionImportPost,
+		},
+		{
+			"SubscriptionExportGet",
 			http.MethodGet,
-			"/subscriptions_list/",
-			apiHandler.SubscriptionListGet,
+			"/subscriptions/export",
+			apiHandler.SubscriptionExportGet,
+		},
+		{
+			"SubscriptionTrashGet",
+			http.MethodGet,
+			"/subscriptions/trash",
+			apiHandler.SubscriptionTrashGet,
+		},
+		{
+			"SubscriptionUserHistoryGet",
+			http.MethodGet,
+			"/subscriptions/history",
+			apiHandler.SubscriptionUserHistoryGet,
+		},
+		{
+			"SubscriptionTotalGet",
+			http.MethodGet,
+			"/subscriptions/total",
+			apiHandler.SubscriptionTotalGet,
+		},
+		{
+			"SubscriptionMonthlySpendGet",
+			http.MethodGet,
+			"/subscriptions/monthly_spend",
+			apiHandler.SubscriptionMonthlySpendGet,
+		},
+		{
+			"SubscriptionRenewalsGet",
+			http.MethodGet,
+			"/subscriptions/upcoming_renewals",
+			apiHandler.SubscriptionRenewalsGet,
+		},
+		{
+			"SubscriptionCancelPost",
+			http.MethodPost,
+			"/subscriptions/:id/cancel",
+			apiHandler.SubscriptionCancelPost,
+		},
+		{
+			"SubscriptionPausePost",
+			http.MethodPost,
+			"/subscriptions/:id/pause",
+			apiHandler.SubscriptionPausePost,
+		},
+		{
+			"SubscriptionResumePost",
+			http.MethodPost,
+			"/subscriptions/:id/resume",
+			apiHandler.SubscriptionResumePost,
+		},
+		{
+			"SubscriptionRestorePost",
+			http.MethodPost,
+			"/subscriptions/:id/restore",
+			apiHandler.SubscriptionRestorePost,
+		},
+		{
+			"SubscriptionHistoryGet",
+			http.MethodGet,
+			"/subscriptions/:id/history",
+			apiHandler.SubscriptionHistoryGet,
+		},
+		{
+			"SubscriptionPriceChangePost",
+			http.MethodPost,
+			"/subscriptions/:id/prices",
+			apiHandler.SubscriptionPriceChangePost,
+		},
+		{
+			"SubscriptionPriceHistoryGet",
+			http.MethodGet,
+			"/subscriptions/:id/prices",
+			apiHandler.SubscriptionPriceHistoryGet,
+		},
+		{
+			"AdminSubscriptionsListGet",
+			http.MethodGet,
+			"/admin/subscriptions",
+			apiHandler.AdminSubscriptionListGet,
+		},
+		{
+			"AdminHistoryGet",
+			http.MethodGet,
+			"/admin/history",
+			apiHandler.AdminHistoryGet,
 		},
 	}
 }
+
+// getLegacyRoutes returns the RPC-style routes that predate the v1 API.
+func getLegacyRoutes(apiHandler handlers.SubscriptionAPI) []LegacyRoute {
+	routes := getRoutes(apiHandler)
+	byName := make(map[string]Route, len(routes))
+	for _, route := range routes {
+		byName[route.Name] = route
+	}
+
+	legacyPatterns := []struct {
+		name    string
+		pattern string
+	}{
+		{"SubscriptionCreatePost", "/create"},
+		{"SubscriptionBulkCreatePost", "/create_bulk"},
+		{"SubscriptionImportPost", "/import_csv"},
+		{"SubscriptionExportGet", "/export_csv/"},
+		{"SubscriptionReadGet", "/read/:id"},
+		{"SubscriptionUpdatePut", "/update_put/:id"},
+		{"SubscriptionUpdatePatch", "/update_patch/:id"},
+		{"SubscriptionDelete", "/delete/:id"},
+		{"SubscriptionCancelPost", "/cancel/:id"},
+		{"SubscriptionPausePost", "/pause/:id"},
+		{"SubscriptionResumePost", "/resume/:id"},
+		{"SubscriptionRestorePost", "/restore/:id"},
+		{"SubscriptionTrashGet", "/trash/"},
+		{"SubscriptionHistoryGet", "/history/:id"},
+		{"SubscriptionUserHistoryGet", "/user_history/"},
+		{"SubscriptionsListGet", "/subscriptions_list/"},
+		{"SubscriptionTotalGet", "/subscriptions_total/"},
+		{"SubscriptionMonthlySpendGet", "/subscriptions_monthly_spend/"},
+		{"SubscriptionPriceChangePost", "/price_change/:id"},
+		{"SubscriptionPriceHistoryGet", "/price_history/:id"},
+		{"SubscriptionRenewalsGet", "/upcoming_renewals/"},
+	}
+
+	legacy := make([]LegacyRoute, 0, len(legacyPatterns))
+	for _, p := range legacyPatterns {
+		route := byName[p.name]
+		legacy = append(legacy, LegacyRoute{
+			Route: Route{
+				Name:        "Legacy" + route.Name,
+				Method:      route.Method,
+				Pattern:     p.pattern,
+				HandlerFunc: route.HandlerFunc,
+			},
+			Successor: route.Pattern,
+		})
+	}
+	return legacy
+}
\ No newline at end of file
@@ -0,0 +1,252 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"github.com/kgugunava/effective_mobile_golang/internal/adapters/postgres"
+
+	"github.com/kgugunava/effective_mobile_golang/internal/api/handlers"
+	api_models "github.com/kgugunava/effective_mobile_golang/internal/api/models"
+	"github.com/kgugunava/effective_mobile_golang/internal/auth"
+	"github.com/kgugunava/effective_mobile_golang/internal/domain"
+	"github.com/kgugunava/effective_mobile_golang/internal/service"
+)
+
+const testJWTSecret = "test-secret"
+
+// newTestRouter serves the API with service behind it, accepting HS256
+// tokens signed with testJWTSecret and the API keys apiKeys verifies.
+func newTestRouter(t *testing.T, service handlers.SubscriptionService, apiKeys APIKeyVerifier) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	logger := slog.New(slog.DiscardHandler)
+
+	verifier, err := auth.NewJWTVerifier(testJWTSecret, nil)
+	if err != nil {
+		t.Fatalf("NewJWTVerifier() error = %v", err)
+	}
+	return NewRouterWithGinEngine(gin.New(), *handlers.NewSubscriptionAPI(service, logger), verifier, apiKeys, logger)
+}
+
+// bearerToken returns an Authorization header value for userID with role.
+func bearerToken(t *testing.T, userID uuid.UUID, role domain.Role) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":  userID.String(),
+		"role": string(role),
+		"exp":  time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(testJWTSecret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return "Bearer " + token
+}
+
+// fakeSubscriptionRepository serves a fixed set of subscriptions. Methods a
+// test does not need panic through the nil embedded interface.
+type fakeSubscriptionRepository struct {
+	service.SubscriptionRepository
+	subscriptions map[uuid.UUID]postgres.SubscriptionEntity
+}
+
+func (r fakeSubscriptionRepository) GetByID(ctx context.Context, id uuid.UUID) (postgres.SubscriptionEntity, error) {
+	subscription, ok := r.subscriptions[id]
+	if !ok {
+		return postgres.SubscriptionEntity{}, postgres.ErrNotFound
+	}
+	return subscription, nil
+}
+
+// fakeAPIKeyVerifier accepts only key and fails with err when it is set.
+type fakeAPIKeyVerifier struct {
+	key       string
+	principal domain.Principal
+	err       error
+}
+
+func (v fakeAPIKeyVerifier) VerifyAPIKey(ctx context.Context, key string) (domain.Principal, error) {
+	if v.err != nil {
+		return domain.Principal{}, v.err
+	}
+	if key != v.key {
+		return domain.Principal{}, auth.ErrInvalidAPIKey
+	}
+	return v.principal, nil
+}
+
+func decodeErrorCode(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var resp api_models.ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode error response: %v", err)
+	}
+	return resp.Error.Code
+}
+
+func TestRouterRequiresCredentials(t *testing.T) {
+	router := newTestRouter(t, nil, nil)
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantCode      string
+	}{
+		{name: "no credentials", wantCode: "UNAUTHENTICATED"},
+		{name: "unknown scheme", authorization: "Basic dXNlcjpwYXNz", wantCode: "UNAUTHENTICATED"},
+		{name: "empty bearer token", authorization: "Bearer ", wantCode: "UNAUTHENTICATED"},
+		{name: "invalid bearer token", authorization: "Bearer not-a-token", wantCode: "INVALID_TOKEN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusUnauthorized {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusUnauthorized, w.Body)
+			}
+			if got := w.Header().Get("WWW-Authenticate"); got == "" {
+				t.Error("WWW-Authenticate header is missing")
+			}
+			if code := decodeErrorCode(t, w); code != tt.wantCode {
+				t.Errorf("error code = %q, want %q", code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestRouterServesDocsWithoutCredentials(t *testing.T) {
+	router := newTestRouter(t, nil, nil)
+
+	for _, path := range []string{"/openapi.json", "/docs", "/docs/assets/swagger-ui.css", "/docs/assets/swagger-ui-bundle.js"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("GET %s status = %d, want %d", path, w.Code, http.StatusOK)
+		}
+		if path == "/docs" && strings.Contains(w.Body.String(), "https://") {
+			t.Errorf("GET /docs loads remote assets: %s", w.Body)
+		}
+	}
+}
+
+func TestRouterForbidsOtherUsersSubscriptions(t *testing.T) {
+	owner := uuid.New()
+	subscription := postgres.SubscriptionEntity{
+		SubscriptionID: uuid.New(),
+		ServiceName:    "Netflix",
+		Price:          400,
+		CurrentPrice:   400,
+		UserID:         owner,
+		StartDate:      time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+		BillingPeriod:  string(domain.BillingPeriodMonthly),
+		Currency:       domain.BaseCurrency,
+		Status:         string(domain.StatusActive),
+		Version:        1,
+	}
+	repo := fakeSubscriptionRepository{subscriptions: map[uuid.UUID]postgres.SubscriptionEntity{subscription.SubscriptionID: subscription}}
+	subscriptions := service.NewSubscriptionService(repo, nil, nil, slog.New(slog.DiscardHandler), time.Hour, time.Hour)
+	router := newTestRouter(t, subscriptions, nil)
+
+	tests := []struct {
+		name       string
+		userID     uuid.UUID
+		role       domain.Role
+		wantStatus int
+	}{
+		{name: "owner", userID: owner, role: domain.RoleUser, wantStatus: http.StatusOK},
+		{name: "another user", userID: uuid.New(), role: domain.RoleUser, wantStatus: http.StatusForbidden},
+		{name: "support", userID: uuid.New(), role: domain.RoleSupport, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions/"+subscription.SubscriptionID.String(), nil)
+			req.Header.Set("Authorization", bearerToken(t, tt.userID, tt.role))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus == http.StatusForbidden {
+				if code := decodeErrorCode(t, w); code != "FORBIDDEN_USER" {
+					t.Errorf("error code = %q, want %q", code, "FORBIDDEN_USER")
+				}
+			}
+		})
+	}
+}
+
+func TestRouterAuthenticatesAPIKeys(t *testing.T) {
+	owner := uuid.New()
+	subscription := postgres.SubscriptionEntity{
+		SubscriptionID: uuid.New(),
+		ServiceName:    "Netflix",
+		Price:          400,
+		CurrentPrice:   400,
+		UserID:         owner,
+		StartDate:      time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+		BillingPeriod:  string(domain.BillingPeriodMonthly),
+		Currency:       domain.BaseCurrency,
+		Status:         string(domain.StatusActive),
+		Version:        1,
+	}
+	repo := fakeSubscriptionRepository{subscriptions: map[uuid.UUID]postgres.SubscriptionEntity{subscription.SubscriptionID: subscription}}
+	subscriptions := service.NewSubscriptionService(repo, nil, nil, slog.New(slog.DiscardHandler), time.Hour, time.Hour)
+
+	tests := []struct {
+		name       string
+		key        string
+		scopes     []domain.Scope
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "read scope", key: "valid", scopes: []domain.Scope{domain.ScopeRead}, wantStatus: http.StatusOK},
+		{name: "write scope only", key: "valid", scopes: []domain.Scope{domain.ScopeWrite}, wantStatus: http.StatusForbidden, wantCode: "FORBIDDEN_USER"},
+		{name: "invalid key", key: "other", wantStatus: http.StatusUnauthorized, wantCode: "INVALID_API_KEY"},
+		{name: "verification failure", key: "valid", err: errors.New("connection refused"), wantStatus: http.StatusInternalServerError, wantCode: "INTERNAL_ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiKeys := fakeAPIKeyVerifier{
+				key:       "valid",
+				principal: domain.Principal{APIKeyID: uuid.New(), Scopes: tt.scopes},
+				err:       tt.err,
+			}
+			router := newTestRouter(t, subscriptions, apiKeys)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions/"+subscription.SubscriptionID.String(), nil)
+			req.Header.Set("Authorization", "ApiKey "+tt.key)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantCode != "" {
+				if code := decodeErrorCode(t, w); code != tt.wantCode {
+					t.Errorf("error code = %q, want %q", code, tt.wantCode)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gin-gonic/gin"
+
+	api_models "github.com/kgugunava/effective_mobile_golang/internal/api/models"
+	"github.com/kgugunava/effective_mobile_golang/internal/api/openapi"
+)
+
+const (
+	// defaultMaxBodySize limits request bodies of operations that do not
+	// declare x-max-body-size on their request body.
+	defaultMaxBodySize   = 1 << 20
+	maxBodySizeExtension = "x-max-body-size"
+)
+
+func init() {
+	// Parameters are validated without per-request schema options, so the
+	// uuid format has to be registered globally. It accepts every UUID
+	// uuid.Parse does in its canonical form, not only RFC 4122 ones.
+	openapi3.DefineStringFormatValidator("uuid", openapi3.NewRegexpFormatValidator(
+		`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`))
+}
+
+// RequestValidator checks requests against the operations of the OpenAPI
+// document they are routed to.
+type RequestValidator struct {
+	doc *openapi3.T
+	// operations are keyed by method and gin route pattern.
+	operations map[string]*routers.Route
+	options    *openapi3filter.Options
+}
+
+func NewRequestValidator(doc *openapi3.T) *RequestValidator {
+	return &RequestValidator{
+		doc:        doc,
+		operations: map[string]*routers.Route{},
+		options: &openapi3filter.Options{
+			MultiError:          true,
+			SkipSettingDefaults: true,
+			// Credentials are checked by Authenticate before validation.
+			AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		},
+	}
+}
+
+// Add validates requests matching the gin pattern against the operation
+// documenting the v1 route, so legacy aliases share the v1 rules.
+func (v *RequestValidator) Add(pattern string, documented Route) error {
+	path := openapi.PathFromPattern(documented.Pattern)
+	item := v.doc.Paths.Value(path)
+	if item == nil {
+		return fmt.Errorf("%s is not documented", path)
+	}
+	op := item.GetOperation(documented.Method)
+	if op == nil {
+		return fmt.Errorf("%s %s is not documented", documented.Method, path)
+	}
+
+	v.operations[documented.Method+" "+pattern] = &routers.Route{
+		Spec:      v.doc,
+		Path:      path,
+		PathItem:  item,
+		Method:    documented.Method,
+		Operation: op,
+	}
+	return nil
+}
+
+// ValidateRequest rejects requests that do not match the documented body
+// schema, path, query or header parameters, or exceed the body size limit,
+// with a 400 response. Each problem is reported with a pointer-style
+// location: /body followed by the JSON pointer of the offending value, or
+// /<in>/<name> for parameters, e.g. /body/price or /query/limit.
+// Requests to undocumented routes are passed through.
+func ValidateRequest(v *RequestValidator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route, ok := v.operations[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			c.Next()
+			return
+		}
+
+		limit := maxBodySize(route.Operation)
+		if c.Request.ContentLength > limit {
+			abortInvalidRequest(c, []api_models.ErrorResponseErrorDetail{{
+				Field:   "/body",
+				Message: fmt.Sprintf("must be at most %d bytes", limit),
+			}})
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+
+		pathParams := make(map[string]string, len(c.Params))
+		for _, param := range c.Params {
+			pathParams[param.Key] = param.Value
+		}
+
+		err := openapi3filter.ValidateRequest(c.Request.Context(), &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    v.options,
+		})
+		if err != nil {
+			abortInvalidRequest(c, validationErrorDetails(err))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func abortInvalidRequest(c *gin.Context, details []api_models.ErrorResponseErrorDetail) {
+	c.AbortWithStatusJSON(http.StatusBadRequest, api_models.ErrorResponse{
+		Error: api_models.ErrorResponseError{
+			Code:    "INVALID_REQUEST",
+			Message: "request does not match the API specification",
+			Details: details,
+		},
+	})
+}
+
+func maxBodySize(op *openapi3.Operation) int64 {
+	if op.RequestBody == nil || op.RequestBody.Value == nil {
+		return defaultMaxBodySize
+	}
+	if size, ok := op.RequestBody.Value.Extensions[maxBodySizeExtension].(float64); ok && size > 0 {
+		return int64(size)
+	}
+	return defaultMaxBodySize
+}
+
+// validationErrorDetails flattens the errors reported by openapi3filter into
+// one detail per offending value.
+func validationErrorDetails(err error) []api_models.ErrorResponseErrorDetail {
+	var details []api_models.ErrorResponseErrorDetail
+	add := func(location string, message string) {
+		if location == "" {
+			location = "/"
+		}
+		details = append(details, api_models.ErrorResponseErrorDetail{Field: location, Message: message})
+	}
+
+	var walk func(location string, err error)
+	walk = func(location string, err error) {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			add("/body", fmt.Sprintf("must be at most %d bytes", maxBytesErr.Limit))
+			return
+		}
+
+		switch e := err.(type) {
+		case openapi3.MultiError:
+			for _, inner := range e {
+				walk(location, inner)
+			}
+		case *openapi3filter.RequestError:
+			location := "/body"
+			if p := e.Parameter; p != nil {
+				location = "/" + p.In + "/" + escapePointerToken(p.Name)
+			}
+			switch inner := e.Err.(type) {
+			case openapi3.MultiError, *openapi3.SchemaError:
+				walk(location, inner)
+				return
+			case nil:
+				add(location, e.Reason)
+				return
+			}
+			message := e.Err.Error()
+			if e.Reason != "" && e.Reason != message {
+				message = e.Reason + ": " + message
+			}
+			add(location, message)
+		case *openapi3.SchemaError:
+			pointer := location
+			for _, token := range e.JSONPointer() {
+				pointer += "/" + escapePointerToken(token)
+			}
+			message := e.Reason
+			if e.SchemaField == "format" && e.Schema != nil {
+				message = fmt.Sprintf("must be a valid %s", e.Schema.Format)
+			}
+			add(pointer, message)
+		default:
+			add(location, err.Error())
+		}
+	}
+	walk("", err)
+	return details
+}
+
+// escapePointerToken escapes a reference token of a JSON pointer (RFC 6901).
+func escapePointerToken(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
@@ -1,7 +1,9 @@
 package app
 
 import (
+	"context"
 	"log/slog"
+	"os"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -9,6 +11,7 @@ import (
 	"github.com/kgugunava/effective_mobile_golang/internal/adapters/postgres"
 	"github.com/kgugunava/effective_mobile_golang/internal/api"
 	"github.com/kgugunava/effective_mobile_golang/internal/api/handlers"
+	"github.com/kgugunava/effective_mobile_golang/internal/auth"
 	"github.com/kgugunava/effective_mobile_golang/internal/config"
 	"github.com/kgugunava/effective_mobile_golang/internal/service"
 )
@@ -47,13 +50,42 @@ func NewApp(db *pgxpool.Pool, logger *slog.Logger, cfg config.Config) *App {
     // app.DB = &db
 
 	subscriptionsRepository := postgres.NewSubscriptionRepository(db, logger)
+	auditRepository := postgres.NewAuditRepository(db, logger)
+	exchangeRatesRepository := postgres.NewExchangeRateRepository(db, logger)
+	apiKeysRepository := postgres.NewAPIKeyRepository(db, logger)
+	txManager := postgres.NewTxManager(db)
 
-	subscriptionsService := service.NewSubscriptionService(subscriptionsRepository, logger)
+	exchangeRatesService := service.NewExchangeRateService(exchangeRatesRepository, logger)
+	if cfg.ExchangeRatesFile != "" {
+		if _, err := exchangeRatesService.LoadRatesFromFile(context.Background(), cfg.ExchangeRatesFile); err != nil {
+			logger.Error("failed to load exchange rates",
+				slog.String("path", cfg.ExchangeRatesFile),
+				slog.Any("error", err),
+			)
+		}
+	}
+
+	subscriptionsService := service.NewSubscriptionService(subscriptionsRepository, auditRepository, txManager, logger, cfg.IdempotencyKeyTTL, cfg.TrashRetention)
+	go subscriptionsService.RunTrashPurge(context.Background(), cfg.TrashPurgeInterval)
+
+	apiKeysService := service.NewAPIKeyService(apiKeysRepository, logger)
 
 	apiSubscriptions := handlers.NewSubscriptionAPI(subscriptionsService, logger)
 
+	var jwtPublicKey []byte
+	if cfg.JWTPublicKeyFile != "" {
+		var err error
+		if jwtPublicKey, err = os.ReadFile(cfg.JWTPublicKeyFile); err != nil {
+			panic(err)
+		}
+	}
+	verifier, err := auth.NewJWTVerifier(cfg.JWTSecret, jwtPublicKey)
+	if err != nil {
+		panic(err)
+	}
+
     
-    app.Router = api.NewRouter(*apiSubscriptions)
+    app.Router = api.NewRouter(*apiSubscriptions, verifier, apiKeysService, logger)
     
     return app
 }
\ No newline at end of file
@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidAPIKey is returned for API keys that are unknown, revoked or
+// expired.
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+const apiKeyBytes = 32
+
+// GenerateAPIKey returns a new random API key. It is shown to the caller once;
+// only its HashAPIKey is stored.
+func GenerateAPIKey() (string, error) {
+	key := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("generate API key: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(key), nil
+}
+
+// HashAPIKey returns the hash an API key is stored and looked up by. Keys are
+// random, so an unsalted hash is enough to keep them from being usable if the
+// database leaks.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
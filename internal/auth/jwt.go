@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"github.com/kgugunava/effective_mobile_golang/internal/domain"
+)
+
+// ErrInvalidToken is returned for tokens that are malformed, expired, signed
+// with an unexpected key or algorithm, or that do not identify a user.
+var ErrInvalidToken = errors.New("invalid token")
+
+// JWTVerifier verifies HS256 tokens signed with a shared secret and RS256
+// tokens signed with the private half of a public key. The token subject is
+// the ID of the authenticated user and the role claim their role, user when
+// absent.
+type JWTVerifier struct {
+	secret    []byte
+	publicKey *rsa.PublicKey
+	parser    *jwt.Parser
+}
+
+// NewJWTVerifier returns a verifier accepting HS256 tokens when secret is set
+// and RS256 tokens when publicKeyPEM is set. At least one is required.
+func NewJWTVerifier(secret string, publicKeyPEM []byte) (*JWTVerifier, error) {
+	v := &JWTVerifier{}
+	var methods []string
+	if secret != "" {
+		v.secret = []byte(secret)
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if len(publicKeyPEM) > 0 {
+		publicKey, err := jwt.ParseRSAPublicKeyFromPEM(publicKeyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("parse JWT public key: %w", err)
+		}
+		v.publicKey = publicKey
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+	if len(methods) == 0 {
+		return nil, errors.New("either a JWT secret or a JWT public key is required")
+	}
+
+	v.parser = jwt.NewParser(jwt.WithValidMethods(methods), jwt.WithExpirationRequired())
+	return v, nil
+}
+
+type claims struct {
+	jwt.RegisteredClaims
+	Role string `json:"role"`
+}
+
+// Verify checks the signature and expiry of token and returns the principal
+// it was issued for.
+func (v *JWTVerifier) Verify(token string) (domain.Principal, error) {
+	var c claims
+	if _, err := v.parser.ParseWithClaims(token, &c, v.key); err != nil {
+		return domain.Principal{}, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	userID, err := uuid.Parse(c.Subject)
+	if err != nil {
+		return domain.Principal{}, fmt.Errorf("%w: subject is not a user ID", ErrInvalidToken)
+	}
+
+	role := domain.RoleUser
+	if c.Role != "" {
+		role = domain.Role(c.Role)
+	}
+	if !role.IsValid() {
+		return domain.Principal{}, fmt.Errorf("%w: unknown role %q", ErrInvalidToken, c.Role)
+	}
+	return domain.Principal{UserID: userID, Role: role}, nil
+}
+
+func (v *JWTVerifier) key(token *jwt.Token) (interface{}, error) {
+	switch token.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		return v.secret, nil
+	case jwt.SigningMethodRS256.Alg():
+		return v.publicKey, nil
+	}
+	return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+}
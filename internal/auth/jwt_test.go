@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"github.com/kgugunava/effective_mobile_golang/internal/domain"
+)
+
+const testSecret = "test-secret"
+
+func signToken(t *testing.T, method jwt.SigningMethod, key interface{}, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return token
+}
+
+func TestJWTVerifierVerify(t *testing.T) {
+	verifier, err := NewJWTVerifier(testSecret, nil)
+	if err != nil {
+		t.Fatalf("NewJWTVerifier() error = %v", err)
+	}
+
+	userID := uuid.New()
+	expiresAt := time.Now().Add(time.Hour).Unix()
+
+	tests := []struct {
+		name    string
+		token   string
+		want    domain.Principal
+		wantErr bool
+	}{
+		{
+			name:  "role defaults to user",
+			token: signToken(t, jwt.SigningMethodHS256, []byte(testSecret), jwt.MapClaims{"sub": userID.String(), "exp": expiresAt}),
+			want:  domain.Principal{UserID: userID, Role: domain.RoleUser},
+		},
+		{
+			name:  "admin role",
+			token: signToken(t, jwt.SigningMethodHS256, []byte(testSecret), jwt.MapClaims{"sub": userID.String(), "exp": expiresAt, "role": "admin"}),
+			want:  domain.Principal{UserID: userID, Role: domain.RoleAdmin},
+		},
+		{
+			name:    "unexpected algorithm",
+			token:   signToken(t, jwt.SigningMethodHS512, []byte(testSecret), jwt.MapClaims{"sub": userID.String(), "exp": expiresAt}),
+			wantErr: true,
+		},
+		{
+			name:    "unsigned",
+			token:   signToken(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, jwt.MapClaims{"sub": userID.String(), "exp": expiresAt}),
+			wantErr: true,
+		},
+		{
+			name:    "wrong secret",
+			token:   signToken(t, jwt.SigningMethodHS256, []byte("other-secret"), jwt.MapClaims{"sub": userID.String(), "exp": expiresAt}),
+			wantErr: true,
+		},
+		{
+			name:    "expired",
+			token:   signToken(t, jwt.SigningMethodHS256, []byte(testSecret), jwt.MapClaims{"sub": userID.String(), "exp": time.Now().Add(-time.Minute).Unix()}),
+			wantErr: true,
+		},
+		{
+			name:    "no expiry",
+			token:   signToken(t, jwt.SigningMethodHS256, []byte(testSecret), jwt.MapClaims{"sub": userID.String()}),
+			wantErr: true,
+		},
+		{
+			name:    "subject is not a user ID",
+			token:   signToken(t, jwt.SigningMethodHS256, []byte(testSecret), jwt.MapClaims{"sub": "alice", "exp": expiresAt}),
+			wantErr: true,
+		},
+		{
+			name:    "unknown role",
+			token:   signToken(t, jwt.SigningMethodHS256, []byte(testSecret), jwt.MapClaims{"sub": userID.String(), "exp": expiresAt, "role": "owner"}),
+			wantErr: true,
+		},
+		{
+			name:    "malformed",
+			token:   "not-a-token",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := verifier.Verify(tt.token)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Fatalf("Verify() error = %v, want %v", err, ErrInvalidToken)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if got.UserID != tt.want.UserID || got.Role != tt.want.Role {
+				t.Errorf("Verify() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewJWTVerifierRequiresKey(t *testing.T) {
+	if _, err := NewJWTVerifier("", nil); err == nil {
+		t.Error("NewJWTVerifier() error = nil, want an error without a secret or public key")
+	}
+}
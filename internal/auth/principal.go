@@ -0,0 +1,26 @@
+// Package auth verifies the credentials of API callers and carries the
+// authenticated principal through a request.
+package auth
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/kgugunava/effective_mobile_golang/internal/domain"
+)
+
+const principalKey = "auth.principal"
+
+// SetPrincipal stores the authenticated caller in the gin context.
+func SetPrincipal(c *gin.Context, principal domain.Principal) {
+	c.Set(principalKey, principal)
+}
+
+// PrincipalFrom returns the authenticated caller stored in the gin context.
+func PrincipalFrom(c *gin.Context) (domain.Principal, bool) {
+	value, ok := c.Get(principalKey)
+	if !ok {
+		return domain.Principal{}, false
+	}
+	principal, ok := value.(domain.Principal)
+	return principal, ok
+}
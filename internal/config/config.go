@@ -1,7 +1,15 @@
 package config
 
 import (
+    "fmt"
     "os"
+    "time"
+)
+
+const (
+    defaultIdempotencyKeyTTL  = 24 * time.Hour
+    defaultTrashRetention     = 30 * 24 * time.Hour
+    defaultTrashPurgeInterval = time.Hour
 )
 
 type Config struct {
@@ -14,20 +22,77 @@ type Config struct {
     SslMode       string `env:"SSL_MODE"`
     DbName        string `env:"DB_NAME"`
     JWTSecret     string `env:"JWT_SECRET"`
+    // JWTPublicKeyFile is a PEM encoded RSA public key verifying RS256 tokens.
+    JWTPublicKeyFile string `env:"JWT_PUBLIC_KEY_FILE"`
+    ExchangeRatesFile string `env:"EXCHANGE_RATES_FILE"`
+    IdempotencyKeyTTL time.Duration `env:"IDEMPOTENCY_KEY_TTL"`
+    TrashRetention time.Duration `env:"TRASH_RETENTION"`
+    TrashPurgeInterval time.Duration `env:"TRASH_PURGE_INTERVAL"`
 }
 
 func NewConfig() Config {
     return Config{}
 }
 
+// InitConfig reads the configuration of the server.
 func (cfg *Config) InitConfig() error {
+    cfg.InitDBConfig()
     cfg.ServerAddress = os.Getenv("SERVER_ADDRESS")
     cfg.Port = os.Getenv("SERVER_PORT")
+    cfg.ExchangeRatesFile = os.Getenv("EXCHANGE_RATES_FILE")
+    cfg.JWTSecret = os.Getenv("JWT_SECRET")
+    cfg.JWTPublicKeyFile = os.Getenv("JWT_PUBLIC_KEY_FILE")
+
+    if cfg.JWTSecret == "" && cfg.JWTPublicKeyFile == "" {
+        return fmt.Errorf("JWT_SECRET or JWT_PUBLIC_KEY_FILE must be set")
+    }
+
+    var err error
+    if cfg.IdempotencyKeyTTL, err = durationFromEnv("IDEMPOTENCY_KEY_TTL", defaultIdempotencyKeyTTL); err != nil {
+        return err
+    }
+    if cfg.TrashRetention, err = durationFromEnv("TRASH_RETENTION", defaultTrashRetention); err != nil {
+        return err
+    }
+    if cfg.TrashPurgeInterval, err = durationFromEnv("TRASH_PURGE_INTERVAL", defaultTrashPurgeInterval); err != nil {
+        return err
+    }
+    return nil
+}
+
+// InitDBConfig reads only the database connection settings, which is all
+// the command line tools need.
+func (cfg *Config) InitDBConfig() {
     cfg.DbUser = os.Getenv("DB_USER")
     cfg.DbPassword = os.Getenv("DB_PASSWORD")
     cfg.DbHost = os.Getenv("DB_HOST")
     cfg.DbPort = os.Getenv("DB_PORT")
     cfg.SslMode = os.Getenv("SSL_MODE")
     cfg.DbName = os.Getenv("DB_NAME")
-    return nil
+}
+
+// DatabaseURL returns the connection URL of the database.
+func (cfg Config) DatabaseURL() string {
+    return fmt.Sprintf("postgresql://%s:%s@%s:%s/%s?sslmode=%s",
+        cfg.DbUser,
+        cfg.DbPassword,
+        cfg.DbHost,
+        cfg.DbPort,
+        cfg.DbName,
+        cfg.SslMode,
+    )
+}
+
+// durationFromEnv reads a positive duration such as "720h" from the
+// environment, falling back to def when the variable is unset.
+func durationFromEnv(name string, def time.Duration) (time.Duration, error) {
+    value := os.Getenv(name)
+    if value == "" {
+        return def, nil
+    }
+    parsed, err := time.ParseDuration(value)
+    if err != nil || parsed <= 0 {
+        return 0, fmt.Errorf("invalid %s %q", name, value)
+    }
+    return parsed, nil
 }
\ No newline at end of file
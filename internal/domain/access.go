@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Role string
+
+const (
+	// RoleUser manages their own subscriptions only.
+	RoleUser Role = "user"
+	// RoleSupport additionally reads the subscriptions of every user.
+	RoleSupport Role = "support"
+	// RoleAdmin manages the subscriptions of every user.
+	RoleAdmin Role = "admin"
+)
+
+func (r Role) IsValid() bool {
+	switch r {
+	case RoleUser, RoleSupport, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+// Scope is a permission granted to an API key. Keys act for every user
+// within their scopes.
+type Scope string
+
+const (
+	// ScopeRead reads the subscriptions of every user.
+	ScopeRead Scope = "read"
+	// ScopeWrite changes the subscriptions of every user.
+	ScopeWrite Scope = "write"
+	// ScopeAdmin grants every other scope and the operations spanning all
+	// users.
+	ScopeAdmin Scope = "admin"
+)
+
+func (s Scope) IsValid() bool {
+	switch s {
+	case ScopeRead, ScopeWrite, ScopeAdmin:
+		return true
+	}
+	return false
+}
+
+// APIKey is a credential of a service calling the API without a user
+// session. Only a hash of the key itself is stored.
+type APIKey struct {
+	KeyID     uuid.UUID
+	Name      string
+	Scopes    []Scope
+	ExpiresAt *time.Time
+	CreatedAt time.Time
+	RevokedAt *time.Time
+}
+
+// Principal is the authenticated caller an operation is performed for. It is
+// either a user with a role or, when APIKeyID is set, an API key with scopes.
+type Principal struct {
+	UserID   uuid.UUID
+	Role     Role
+	APIKeyID uuid.UUID
+	Scopes   []Scope
+}
+
+// IsAPIKey reports whether p was authenticated with an API key.
+func (p Principal) IsAPIKey() bool {
+	return p.APIKeyID != uuid.Nil
+}
+
+// CanRead reports whether p may read the subscriptions of the given user.
+func (p Principal) CanRead(userID uuid.UUID) bool {
+	if p.IsAPIKey() {
+		return p.hasScope(ScopeRead)
+	}
+	return p.Role == RoleAdmin || p.Role == RoleSupport || p.UserID == userID
+}
+
+// CanWrite reports whether p may change the subscriptions of the given user.
+func (p Principal) CanWrite(userID uuid.UUID) bool {
+	if p.IsAPIKey() {
+		return p.hasScope(ScopeWrite)
+	}
+	return p.Role == RoleAdmin || p.UserID == userID
+}
+
+// IsAdmin reports whether p may use operations spanning all users.
+func (p Principal) IsAdmin() bool {
+	if p.IsAPIKey() {
+		return p.hasScope(ScopeAdmin)
+	}
+	return p.Role == RoleAdmin
+}
+
+func (p Principal) hasScope(scope Scope) bool {
+	return slices.Contains(p.Scopes, scope) || slices.Contains(p.Scopes, ScopeAdmin)
+}
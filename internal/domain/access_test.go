@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestPrincipalAccess(t *testing.T) {
+	owner := uuid.New()
+	other := uuid.New()
+
+	tests := []struct {
+		name      string
+		principal Principal
+		userID    uuid.UUID
+		wantRead  bool
+		wantWrite bool
+		wantAdmin bool
+	}{
+		{name: "user, own subscriptions", principal: Principal{UserID: owner, Role: RoleUser}, userID: owner, wantRead: true, wantWrite: true},
+		{name: "user, another user", principal: Principal{UserID: owner, Role: RoleUser}, userID: other},
+		{name: "support, own subscriptions", principal: Principal{UserID: owner, Role: RoleSupport}, userID: owner, wantRead: true, wantWrite: true},
+		{name: "support, another user", principal: Principal{UserID: owner, Role: RoleSupport}, userID: other, wantRead: true},
+		{name: "admin, another user", principal: Principal{UserID: owner, Role: RoleAdmin}, userID: other, wantRead: true, wantWrite: true, wantAdmin: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.principal.CanRead(tt.userID); got != tt.wantRead {
+				t.Errorf("CanRead() = %v, want %v", got, tt.wantRead)
+			}
+			if got := tt.principal.CanWrite(tt.userID); got != tt.wantWrite {
+				t.Errorf("CanWrite() = %v, want %v", got, tt.wantWrite)
+			}
+			if got := tt.principal.IsAdmin(); got != tt.wantAdmin {
+				t.Errorf("IsAdmin() = %v, want %v", got, tt.wantAdmin)
+			}
+		})
+	}
+}
+
+func TestAPIKeyPrincipalAccess(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name      string
+		scopes    []Scope
+		wantRead  bool
+		wantWrite bool
+		wantAdmin bool
+	}{
+		{name: "no scopes"},
+		{name: "read", scopes: []Scope{ScopeRead}, wantRead: true},
+		{name: "write", scopes: []Scope{ScopeWrite}, wantWrite: true},
+		{name: "read and write", scopes: []Scope{ScopeRead, ScopeWrite}, wantRead: true, wantWrite: true},
+		{name: "admin grants every scope", scopes: []Scope{ScopeAdmin}, wantRead: true, wantWrite: true, wantAdmin: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The user ID and role of a key principal are ignored.
+			principal := Principal{UserID: userID, Role: RoleAdmin, APIKeyID: uuid.New(), Scopes: tt.scopes}
+			if got := principal.CanRead(userID); got != tt.wantRead {
+				t.Errorf("CanRead() = %v, want %v", got, tt.wantRead)
+			}
+			if got := principal.CanWrite(userID); got != tt.wantWrite {
+				t.Errorf("CanWrite() = %v, want %v", got, tt.wantWrite)
+			}
+			if got := principal.IsAdmin(); got != tt.wantAdmin {
+				t.Errorf("IsAdmin() = %v, want %v", got, tt.wantAdmin)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditAction string
+
+const (
+	AuditActionCreate      AuditAction = "create"
+	AuditActionUpdate      AuditAction = "update"
+	AuditActionPatch       AuditAction = "patch"
+	AuditActionDelete      AuditAction = "delete"
+	AuditActionRestore     AuditAction = "restore"
+	AuditActionCancel      AuditAction = "cancel"
+	AuditActionPause       AuditAction = "pause"
+	AuditActionResume      AuditAction = "resume"
+	// AuditActionPriceChange records a scheduled price change. Before and
+	// After differ only when it changes the current price.
+	AuditActionPriceChange AuditAction = "price_change"
+)
+
+// AuditEntry records one change of a subscription. Before is nil for a
+// create and After is nil for a delete.
+type AuditEntry struct {
+	AuditID int64
+	SubscriptionID uuid.UUID
+	UserID uuid.UUID
+	Action AuditAction
+	Actor string
+	RequestID string
+	Before *Subscription
+	After *Subscription
+	CreatedAt time.Time
+}
+
+// AuditQuery selects the history of a subscription or, when SubscriptionID
+// is nil, of a user. Before, when set, continues a previous page.
+type AuditQuery struct {
+	SubscriptionID *uuid.UUID
+	UserID *uuid.UUID
+	Before *int64
+	Limit int
+}
+
+// AuditPage holds audit entries newest first. NextBefore is set when older
+// entries remain.
+type AuditPage struct {
+	Entries []AuditEntry
+	NextBefore *int64
+}
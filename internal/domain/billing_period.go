@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"time"
+)
+
+type BillingPeriod string
+
+const (
+	BillingPeriodWeekly    BillingPeriod = "weekly"
+	BillingPeriodMonthly   BillingPeriod = "monthly"
+	BillingPeriodQuarterly BillingPeriod = "quarterly"
+	BillingPeriodYearly    BillingPeriod = "yearly"
+)
+
+func (p BillingPeriod) IsValid() bool {
+	switch p {
+	case BillingPeriodWeekly, BillingPeriodMonthly, BillingPeriodQuarterly, BillingPeriodYearly:
+		return true
+	}
+	return false
+}
+
+func (p BillingPeriod) months() int {
+	switch p {
+	case BillingPeriodQuarterly:
+		return 3
+	case BillingPeriodYearly:
+		return 12
+	case BillingPeriodWeekly:
+		return 0
+	}
+	return 1
+}
+
+// ChargeDate returns the n-th (0-based) charge date of a subscription that
+// started on start. Month based periods keep the day of month of start and
+// fall back to the last day of shorter months.
+func (p BillingPeriod) ChargeDate(start time.Time, n int) time.Time {
+	if p == BillingPeriodWeekly {
+		return start.AddDate(0, 0, 7*n)
+	}
+	return addMonthsClamped(start, n*p.months())
+}
+
+// ChargeDatesBetween returns the charge dates of a subscription that started
+// on start and ends on end (nil for open-ended) falling into [from, to].
+// Like the cost reports, a subscription is active through the whole month
+// of its end date.
+func (p BillingPeriod) ChargeDatesBetween(start time.Time, end *time.Time, from time.Time, to time.Time) []time.Time {
+	if end != nil {
+		lastDay := time.Date(end.Year(), end.Month()+1, 0, 0, 0, 0, 0, end.Location())
+		if lastDay.Before(to) {
+			to = lastDay
+		}
+	}
+
+	n := 0
+	if from.After(start) {
+		if p == BillingPeriodWeekly {
+			n = int(from.Sub(start).Hours()/24) / 7
+		} else {
+			n = monthsBetween(start, from) / p.months()
+		}
+		n = max(n-1, 0)
+	}
+
+	dates := []time.Time{}
+	for date := p.ChargeDate(start, n); !date.After(to); date = p.ChargeDate(start, n) {
+		if !date.Before(from) {
+			dates = append(dates, date)
+		}
+		n++
+	}
+
+	return dates
+}
+
+func addMonthsClamped(t time.Time, months int) time.Time {
+	firstOfMonth := time.Date(t.Year(), t.Month()+time.Month(months), 1, 0, 0, 0, 0, t.Location())
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+	return time.Date(firstOfMonth.Year(), firstOfMonth.Month(), min(t.Day(), lastDay), 0, 0, 0, 0, t.Location())
+}
+
+func monthsBetween(from time.Time, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestChargeDatesBetween(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	endDate := date(2025, time.March, 5)
+
+	tests := []struct {
+		name   string
+		period BillingPeriod
+		start  time.Time
+		end    *time.Time
+		from   time.Time
+		to     time.Time
+		want   []time.Time
+	}{
+		{
+			name:   "monthly clamped to shorter months",
+			period: BillingPeriodMonthly,
+			start:  date(2025, time.January, 31),
+			from:   date(2025, time.February, 1),
+			to:     date(2025, time.April, 30),
+			want:   []time.Time{date(2025, time.February, 28), date(2025, time.March, 31), date(2025, time.April, 30)},
+		},
+		{
+			name:   "monthly active through the month of its end",
+			period: BillingPeriodMonthly,
+			start:  date(2025, time.January, 10),
+			end:    &endDate,
+			from:   date(2025, time.January, 1),
+			to:     date(2025, time.December, 31),
+			want:   []time.Time{date(2025, time.January, 10), date(2025, time.February, 10), date(2025, time.March, 10)},
+		},
+		{
+			name:   "quarterly",
+			period: BillingPeriodQuarterly,
+			start:  date(2025, time.January, 15),
+			from:   date(2025, time.January, 1),
+			to:     date(2025, time.December, 31),
+			want:   []time.Time{date(2025, time.January, 15), date(2025, time.April, 15), date(2025, time.July, 15), date(2025, time.October, 15)},
+		},
+		{
+			name:   "yearly from a leap day",
+			period: BillingPeriodYearly,
+			start:  date(2024, time.February, 29),
+			from:   date(2025, time.January, 1),
+			to:     date(2026, time.December, 31),
+			want:   []time.Time{date(2025, time.February, 28), date(2026, time.February, 28)},
+		},
+		{
+			name:   "weekly",
+			period: BillingPeriodWeekly,
+			start:  date(2025, time.January, 1),
+			from:   date(2025, time.January, 10),
+			to:     date(2025, time.January, 31),
+			want:   []time.Time{date(2025, time.January, 15), date(2025, time.January, 22), date(2025, time.January, 29)},
+		},
+		{
+			name:   "starts after the range",
+			period: BillingPeriodMonthly,
+			start:  date(2026, time.January, 1),
+			from:   date(2025, time.January, 1),
+			to:     date(2025, time.December, 31),
+			want:   []time.Time{},
+		},
+		{
+			name:   "ended before the range",
+			period: BillingPeriodMonthly,
+			start:  date(2024, time.January, 10),
+			end:    &endDate,
+			from:   date(2025, time.June, 1),
+			to:     date(2025, time.December, 31),
+			want:   []time.Time{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.period.ChargeDatesBetween(tt.start, tt.end, tt.from, tt.to)
+			if !slices.EqualFunc(got, tt.want, time.Time.Equal) {
+				t.Errorf("ChargeDatesBetween() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
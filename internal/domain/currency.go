@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"time"
+)
+
+// BaseCurrency is the currency exchange rates are quoted against.
+const BaseCurrency = "RUB"
+
+var knownCurrencies = map[string]bool{
+	"RUB": true,
+	"USD": true,
+	"EUR": true,
+}
+
+func IsKnownCurrency(code string) bool {
+	return knownCurrencies[code]
+}
+
+// ExchangeRate is the price of one unit of Currency in BaseCurrency,
+// valid from EffectiveFrom until the next rate of the same currency.
+type ExchangeRate struct {
+	Currency string
+	Rate float64
+	EffectiveFrom time.Time
+}
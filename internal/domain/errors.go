@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error kinds. Every error returned by the service layer that is caused by
+// the request rather than by the infrastructure wraps one of them.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrInvalidInput = errors.New("invalid input")
+	ErrValidation   = errors.New("validation failed")
+	ErrConflict     = errors.New("conflict")
+	ErrForbidden    = errors.New("forbidden")
+	// ErrPreconditionFailed reports a write whose expected version did not
+	// match the stored one.
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+// Error is a domain error of a given kind with a stable machine readable code
+// and, for validation errors, the offending fields.
+type Error struct {
+	Kind    error
+	Code    string
+	Message string
+	Details []FieldError
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+func newError(kind error, code string, format string, args ...interface{}) error {
+	return &Error{
+		Kind:    kind,
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+func NotFoundError(code string, format string, args ...interface{}) error {
+	return newError(ErrNotFound, code, format, args...)
+}
+
+func InvalidInputError(code string, format string, args ...interface{}) error {
+	return newError(ErrInvalidInput, code, format, args...)
+}
+
+func ValidationError(code string, format string, args ...interface{}) error {
+	return newError(ErrValidation, code, format, args...)
+}
+
+func ConflictError(code string, format string, args ...interface{}) error {
+	return newError(ErrConflict, code, format, args...)
+}
+
+func PreconditionFailedError(code string, format string, args ...interface{}) error {
+	return newError(ErrPreconditionFailed, code, format, args...)
+}
+
+func ForbiddenError(code string, format string, args ...interface{}) error {
+	return newError(ErrForbidden, code, format, args...)
+}
@@ -0,0 +1,16 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Renewal struct {
+	SubscriptionID uuid.UUID
+	ServiceName string
+	ChargeDate time.Time
+	Price int
+	Currency string
+	BillingPeriod BillingPeriod
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CostBasis selects how subscription prices are turned into costs.
+// Normalized spreads every price evenly as a monthly equivalent,
+// Charged counts the price only in months that contain an actual charge date.
+type CostBasis string
+
+const (
+	CostBasisNormalized CostBasis = "normalized"
+	CostBasisCharged    CostBasis = "charged"
+)
+
+func (b CostBasis) IsValid() bool {
+	return b == CostBasisNormalized || b == CostBasisCharged
+}
+
+type CostQuery struct {
+	UserID uuid.UUID
+	ServiceName string
+	StartDate time.Time
+	EndDate time.Time
+	Basis CostBasis
+	Currency string
+}
+
+type ServiceSpend struct {
+	ServiceName string
+	Total int64
+}
+
+type MonthlySpend struct {
+	Month time.Time
+	Total int64
+	Services []ServiceSpend
+}
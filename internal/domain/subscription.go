@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,4 +14,41 @@ type Subscription struct {
 	UserID uuid.UUID
 	StartDate time.Time
 	EndDate *time.Time
+	BillingPeriod BillingPeriod
+	Currency string
+	Status SubscriptionStatus
+	Version int
+	UpdatedAt time.Time
+	// DeletedAt is set while the subscription is in the trash.
+	DeletedAt *time.Time
+}
+
+// Validate checks the subscription as a whole and returns a validation error
+// listing every invalid field.
+func (s *Subscription) Validate() error {
+	var errs FieldErrors
+
+	if strings.TrimSpace(s.ServiceName) == "" {
+		errs.Add("service_name", "must not be empty")
+	}
+	if s.Price <= 0 {
+		errs.Add("price", "must be > 0")
+	}
+	if s.UserID == uuid.Nil {
+		errs.Add("user_id", "must not be empty")
+	}
+	if s.StartDate.IsZero() {
+		errs.Add("start_date", "must not be empty")
+	}
+	if s.EndDate != nil && s.EndDate.Before(s.StartDate) {
+		errs.Add("end_date", "must not be before start_date")
+	}
+	if !s.BillingPeriod.IsValid() {
+		errs.Add("billing_period", "must be one of: weekly, monthly, quarterly, yearly")
+	}
+	if !IsKnownCurrency(s.Currency) {
+		errs.Add("currency", "unknown currency code")
+	}
+
+	return errs.Err()
 }
\ No newline at end of file
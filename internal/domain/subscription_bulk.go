@@ -0,0 +1,46 @@
+package domain
+
+// BulkCreateMode selects how a bulk create reacts to failing items.
+type BulkCreateMode string
+
+const (
+	// BulkCreateAtomic creates either every item or none of them.
+	BulkCreateAtomic BulkCreateMode = "atomic"
+	// BulkCreatePartial creates every item that can be created and reports
+	// the others.
+	BulkCreatePartial BulkCreateMode = "partial"
+)
+
+func (m BulkCreateMode) IsValid() bool {
+	switch m {
+	case BulkCreateAtomic, BulkCreatePartial:
+		return true
+	}
+	return false
+}
+
+type BulkItemStatus string
+
+const (
+	BulkItemCreated BulkItemStatus = "created"
+	BulkItemFailed  BulkItemStatus = "failed"
+	// BulkItemSkipped marks a valid item that was not created because
+	// another item of an atomic request failed.
+	BulkItemSkipped BulkItemStatus = "skipped"
+	// BulkItemValid marks an item that passed validation in a dry run.
+	BulkItemValid BulkItemStatus = "valid"
+)
+
+// BulkCreateItem is one element of a bulk create request. Err is set when the
+// item could not be turned into a subscription in the first place.
+type BulkCreateItem struct {
+	Subscription *Subscription
+	Err error
+}
+
+// BulkCreateResult is the outcome of the bulk create item at the same index.
+type BulkCreateResult struct {
+	Status BulkItemStatus
+	Subscription *Subscription
+	Err error
+}
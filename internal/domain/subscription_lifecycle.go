@@ -0,0 +1,177 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SubscriptionStatus string
+
+const (
+	StatusActive    SubscriptionStatus = "active"
+	StatusPaused    SubscriptionStatus = "paused"
+	StatusCancelled SubscriptionStatus = "cancelled"
+)
+
+type LifecycleAction string
+
+const (
+	LifecycleCancel LifecycleAction = "cancel"
+	LifecyclePause  LifecycleAction = "pause"
+	LifecycleResume LifecycleAction = "resume"
+)
+
+// transitions lists the status every lifecycle action leads to from each
+// status it is allowed in. A cancelled subscription is final.
+var transitions = map[SubscriptionStatus]map[LifecycleAction]SubscriptionStatus{
+	StatusActive: {
+		LifecycleCancel: StatusCancelled,
+		LifecyclePause:  StatusPaused,
+	},
+	StatusPaused: {
+		LifecycleCancel: StatusCancelled,
+		LifecycleResume: StatusActive,
+	},
+}
+
+// Transition returns the status a subscription in status s gets by action,
+// or a conflict error when the action is not allowed in s.
+func (s SubscriptionStatus) Transition(action LifecycleAction) (SubscriptionStatus, error) {
+	next, ok := transitions[s][action]
+	if !ok {
+		return s, ConflictError("ILLEGAL_TRANSITION", "cannot %s a subscription that is %s", action, s)
+	}
+	return next, nil
+}
+
+// TransitionAt returns the status the subscription gets by action at now,
+// given its pauses. Besides the transitions of its status, an active
+// subscription with a pause scheduled for a later month can be resumed,
+// which withdraws that pause.
+func (s Subscription) TransitionAt(action LifecycleAction, pauses []SubscriptionPause, now time.Time) (SubscriptionStatus, error) {
+	if s.Status == StatusActive && action == LifecycleResume && hasScheduledPause(pauses, now) {
+		return StatusActive, nil
+	}
+	return s.Status.Transition(action)
+}
+
+// CancelMode tells when a cancellation takes effect.
+type CancelMode string
+
+const (
+	// CancelNow ends the subscription with the current month.
+	CancelNow CancelMode = "now"
+	// CancelAtPeriodEnd ends the subscription with the last month of the
+	// billing period that is running.
+	CancelAtPeriodEnd CancelMode = "period_end"
+)
+
+func (m CancelMode) IsValid() bool {
+	switch m {
+	case CancelNow, CancelAtPeriodEnd:
+		return true
+	}
+	return false
+}
+
+// CancelEndDate returns the end date the subscription gets when it is
+// cancelled at now. An earlier end date the subscription already has is kept.
+func (s Subscription) CancelEndDate(now time.Time, mode CancelMode) time.Time {
+	end := firstOfMonth(now)
+
+	if months := s.BillingPeriod.months(); mode == CancelAtPeriodEnd && months > 1 && !now.Before(s.StartDate) {
+		n := monthsBetween(s.StartDate, now) / months
+		if s.BillingPeriod.ChargeDate(s.StartDate, n).After(now) {
+			n--
+		}
+		next := s.BillingPeriod.ChargeDate(s.StartDate, n+1)
+		end = firstOfMonth(next).AddDate(0, -1, 0)
+	}
+
+	if start := firstOfMonth(s.StartDate); end.Before(start) {
+		end = start
+	}
+	if s.EndDate != nil && s.EndDate.Before(end) {
+		end = *s.EndDate
+	}
+	return end
+}
+
+// SubscriptionPause is a range of months, both ends included, in which the
+// subscription is not charged.
+type SubscriptionPause struct {
+	SubscriptionID uuid.UUID
+	StartMonth time.Time
+	EndMonth time.Time
+}
+
+// Covers reports whether date falls in one of the months of the pause.
+func (p SubscriptionPause) Covers(date time.Time) bool {
+	month := firstOfMonth(date)
+	return !month.Before(p.StartMonth) && !month.After(p.EndMonth)
+}
+
+// Overlaps reports whether the pause shares a month with the range from the
+// month of from through the month of to.
+func (p SubscriptionPause) Overlaps(from time.Time, to time.Time) bool {
+	return !firstOfMonth(from).After(p.EndMonth) && !firstOfMonth(to).Before(p.StartMonth)
+}
+
+func hasScheduledPause(pauses []SubscriptionPause, now time.Time) bool {
+	month := firstOfMonth(now)
+	for _, pause := range pauses {
+		if pause.StartMonth.After(month) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateDatesChange checks that the start and end date of the subscription
+// can be changed to those of updated. Once it is paused or cancelled they
+// are managed by the lifecycle actions, so that an update cannot reopen a
+// cancelled subscription or end one in the middle of its pause.
+func (s Subscription) ValidateDatesChange(updated Subscription) error {
+	if s.Status == StatusActive {
+		return nil
+	}
+	sameEnd := s.EndDate == nil && updated.EndDate == nil ||
+		s.EndDate != nil && updated.EndDate != nil && s.EndDate.Equal(*updated.EndDate)
+	if !s.StartDate.Equal(updated.StartDate) || !sameEnd {
+		return ConflictError("ILLEGAL_TRANSITION", "cannot change the dates of a subscription that is %s", s.Status)
+	}
+	return nil
+}
+
+// ValidatePause checks that the subscription can be paused from the month
+// of from through the month of to, given that it is now and that it already
+// has pauses. Pauses must not share a month.
+func (s Subscription) ValidatePause(from time.Time, to time.Time, now time.Time, pauses []SubscriptionPause) error {
+	var errs FieldErrors
+
+	if firstOfMonth(from).Before(firstOfMonth(now)) {
+		errs.Add("start_date", "must not be in the past")
+	}
+	if firstOfMonth(from).Before(firstOfMonth(s.StartDate)) {
+		errs.Add("start_date", "must not be before the start of the subscription")
+	}
+	if to.Before(from) {
+		errs.Add("end_date", "must not be before start_date")
+	}
+	if s.EndDate != nil && firstOfMonth(to).After(firstOfMonth(*s.EndDate)) {
+		errs.Add("end_date", "must not be after the end of the subscription")
+	}
+	for _, pause := range pauses {
+		if !to.Before(from) && pause.Overlaps(from, to) {
+			errs.Add("start_date", "must not overlap the pause from "+pause.StartMonth.Format("01-2006")+" through "+pause.EndMonth.Format("01-2006"))
+			break
+		}
+	}
+
+	return errs.Err()
+}
+
+func firstOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,183 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStatusTransition(t *testing.T) {
+	tests := []struct {
+		status  SubscriptionStatus
+		action  LifecycleAction
+		want    SubscriptionStatus
+		wantErr bool
+	}{
+		{status: StatusActive, action: LifecycleCancel, want: StatusCancelled},
+		{status: StatusActive, action: LifecyclePause, want: StatusPaused},
+		{status: StatusActive, action: LifecycleResume, wantErr: true},
+		{status: StatusPaused, action: LifecycleCancel, want: StatusCancelled},
+		{status: StatusPaused, action: LifecycleResume, want: StatusActive},
+		{status: StatusPaused, action: LifecyclePause, wantErr: true},
+		{status: StatusCancelled, action: LifecycleCancel, wantErr: true},
+		{status: StatusCancelled, action: LifecyclePause, wantErr: true},
+		{status: StatusCancelled, action: LifecycleResume, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status)+"/"+string(tt.action), func(t *testing.T) {
+			got, err := tt.status.Transition(tt.action)
+			if tt.wantErr {
+				if !errors.Is(err, ErrConflict) {
+					t.Fatalf("Transition() error = %v, want a conflict", err)
+				}
+				if got != tt.status {
+					t.Errorf("Transition() = %q, want the status to stay %q", got, tt.status)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Transition() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Transition() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubscriptionPauseCovers(t *testing.T) {
+	pause := SubscriptionPause{
+		StartMonth: time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC),
+		EndMonth:   time.Date(2025, time.May, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		date time.Time
+		want bool
+	}{
+		{date: time.Date(2025, time.February, 28, 0, 0, 0, 0, time.UTC), want: false},
+		{date: time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC), want: true},
+		{date: time.Date(2025, time.April, 15, 0, 0, 0, 0, time.UTC), want: true},
+		{date: time.Date(2025, time.May, 31, 0, 0, 0, 0, time.UTC), want: true},
+		{date: time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.date.Format(time.DateOnly), func(t *testing.T) {
+			if got := pause.Covers(tt.date); got != tt.want {
+				t.Errorf("Covers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateDatesChange(t *testing.T) {
+	start := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)
+	later := time.Date(2025, time.December, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		status  SubscriptionStatus
+		end     *time.Time
+		updated Subscription
+		wantErr bool
+	}{
+		{name: "active, dates changed", status: StatusActive, end: &end, updated: Subscription{StartDate: start.AddDate(0, 1, 0), EndDate: &later}},
+		{name: "paused, dates kept", status: StatusPaused, updated: Subscription{StartDate: start}},
+		{name: "paused, end date set", status: StatusPaused, updated: Subscription{StartDate: start, EndDate: &end}, wantErr: true},
+		{name: "paused, start date moved", status: StatusPaused, updated: Subscription{StartDate: start.AddDate(0, 1, 0)}, wantErr: true},
+		{name: "cancelled, dates kept", status: StatusCancelled, end: &end, updated: Subscription{StartDate: start, EndDate: &end}},
+		{name: "cancelled, end date removed", status: StatusCancelled, end: &end, updated: Subscription{StartDate: start}, wantErr: true},
+		{name: "cancelled, end date moved", status: StatusCancelled, end: &end, updated: Subscription{StartDate: start, EndDate: &later}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := Subscription{StartDate: start, EndDate: tt.end, Status: tt.status}
+			err := current.ValidateDatesChange(tt.updated)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("ValidateDatesChange() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrConflict) {
+				t.Errorf("ValidateDatesChange() error = %v, want a conflict", err)
+			}
+		})
+	}
+}
+
+func TestSubscriptionTransitionAt(t *testing.T) {
+	now := time.Date(2025, time.March, 15, 0, 0, 0, 0, time.UTC)
+	scheduled := []SubscriptionPause{{StartMonth: time.Date(2025, time.May, 1, 0, 0, 0, 0, time.UTC), EndMonth: time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)}}
+	running := []SubscriptionPause{{StartMonth: time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC), EndMonth: time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC)}}
+
+	tests := []struct {
+		name    string
+		status  SubscriptionStatus
+		action  LifecycleAction
+		pauses  []SubscriptionPause
+		want    SubscriptionStatus
+		wantErr bool
+	}{
+		{name: "resume withdraws a scheduled pause", status: StatusActive, action: LifecycleResume, pauses: scheduled, want: StatusActive},
+		{name: "resume without a scheduled pause", status: StatusActive, action: LifecycleResume, wantErr: true},
+		{name: "resume a running pause", status: StatusPaused, action: LifecycleResume, pauses: running, want: StatusActive},
+		{name: "pause with a scheduled pause", status: StatusActive, action: LifecyclePause, pauses: scheduled, want: StatusPaused},
+		{name: "resume a cancelled subscription", status: StatusCancelled, action: LifecycleResume, pauses: scheduled, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Subscription{Status: tt.status}.TransitionAt(tt.action, tt.pauses, now)
+			if tt.wantErr {
+				if !errors.Is(err, ErrConflict) {
+					t.Fatalf("TransitionAt() error = %v, want a conflict", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TransitionAt() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("TransitionAt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidatePause(t *testing.T) {
+	month := func(m time.Month) time.Time {
+		return time.Date(2025, m, 1, 0, 0, 0, 0, time.UTC)
+	}
+	now := time.Date(2025, time.March, 15, 0, 0, 0, 0, time.UTC)
+	subscription := Subscription{StartDate: month(time.January), Status: StatusActive}
+	pauses := []SubscriptionPause{{StartMonth: month(time.May), EndMonth: month(time.June)}}
+
+	tests := []struct {
+		name    string
+		from    time.Time
+		to      time.Time
+		wantErr bool
+	}{
+		{name: "before an existing pause", from: month(time.March), to: month(time.April)},
+		{name: "after an existing pause", from: month(time.July), to: month(time.August)},
+		{name: "same start month", from: month(time.May), to: month(time.May), wantErr: true},
+		{name: "ends inside an existing pause", from: month(time.April), to: month(time.May), wantErr: true},
+		{name: "surrounds an existing pause", from: month(time.April), to: month(time.July), wantErr: true},
+		{name: "in the past", from: month(time.February), to: month(time.March), wantErr: true},
+		{name: "ends before it starts", from: month(time.August), to: month(time.July), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := subscription.ValidatePause(tt.from, tt.to, now, pauses)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("ValidatePause() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrValidation) {
+				t.Errorf("ValidatePause() error = %v, want a validation error", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SortField string
+
+const (
+	SortByPrice       SortField = "price"
+	SortByStartDate   SortField = "start_date"
+	SortByEndDate     SortField = "end_date"
+	SortByServiceName SortField = "service_name"
+)
+
+func (f SortField) IsValid() bool {
+	switch f {
+	case SortByPrice, SortByStartDate, SortByEndDate, SortByServiceName:
+		return true
+	}
+	return false
+}
+
+type SortOrder string
+
+const (
+	SortOrderAsc  SortOrder = "asc"
+	SortOrderDesc SortOrder = "desc"
+)
+
+func (o SortOrder) IsValid() bool {
+	return o == SortOrderAsc || o == SortOrderDesc
+}
+
+// ListCursor points right after the last row of a page. It is bound to the
+// sort it was produced with and is opaque to clients.
+type ListCursor struct {
+	SortBy SortField `json:"s"`
+	Order SortOrder `json:"o"`
+	Value string `json:"v"`
+	SubscriptionID uuid.UUID `json:"id"`
+}
+
+func EncodeListCursor(cursor ListCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeListCursor(s string) (ListCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return ListCursor{}, fmt.Errorf("malformed cursor")
+	}
+
+	var cursor ListCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return ListCursor{}, fmt.Errorf("malformed cursor")
+	}
+	if !cursor.SortBy.IsValid() || !cursor.Order.IsValid() || !validCursorValue(cursor.SortBy, cursor.Value) {
+		return ListCursor{}, fmt.Errorf("malformed cursor")
+	}
+
+	return cursor, nil
+}
+
+// validCursorValue reports whether value is a sort key of the given field in
+// the text form EncodeListCursor produces, so that it can be compared with
+// the keys of the rows.
+func validCursorValue(sortBy SortField, value string) bool {
+	switch sortBy {
+	case SortByPrice:
+		_, err := strconv.Atoi(value)
+		return err == nil
+	case SortByStartDate:
+		_, err := time.Parse(time.DateOnly, value)
+		return err == nil
+	case SortByEndDate:
+		if value == "infinity" {
+			return true
+		}
+		_, err := time.Parse(time.DateOnly, value)
+		return err == nil
+	}
+	return true
+}
+
+type NameMatch string
+
+const (
+	NameMatchExact    NameMatch = "exact"
+	NameMatchIExact   NameMatch = "iexact"
+	NameMatchPrefix   NameMatch = "prefix"
+	NameMatchContains NameMatch = "contains"
+)
+
+func (m NameMatch) IsValid() bool {
+	switch m {
+	case NameMatchExact, NameMatchIExact, NameMatchPrefix, NameMatchContains:
+		return true
+	}
+	return false
+}
+
+// PeriodMode selects how StartDate/EndDate of a list query restrict
+// subscriptions. Contains keeps subscriptions that start and end inside the
+// period, where an end bound does not exclude open-ended subscriptions.
+// Overlaps keeps subscriptions active in at least one month of the period.
+type PeriodMode string
+
+const (
+	PeriodModeContains PeriodMode = "contains"
+	PeriodModeOverlaps PeriodMode = "overlaps"
+)
+
+func (m PeriodMode) IsValid() bool {
+	return m == PeriodModeContains || m == PeriodModeOverlaps
+}
+
+type SubscriptionListQuery struct {
+	ServiceName string
+	ServiceNameMatch NameMatch
+	UserID uuid.UUID
+	StartDate time.Time
+	EndDate time.Time
+	PeriodMode PeriodMode
+	ActiveAt *time.Time
+	MinPrice *int
+	MaxPrice *int
+	HasEndDate *bool
+	// Deleted lists the trash instead of the live subscriptions.
+	Deleted bool
+	SortBy SortField
+	Order SortOrder
+	Limit int
+	After *ListCursor
+}
+
+type SubscriptionPage struct {
+	Subscriptions []Subscription
+	NextCursor *ListCursor
+	TotalCount int64
+}
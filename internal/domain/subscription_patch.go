@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SubscriptionPatch holds the fields of a partial update. Nil fields are left
+// untouched, ClearEndDate reopens the subscription by removing its end date.
+type SubscriptionPatch struct {
+	ServiceName *string
+	Price *int
+	UserID *uuid.UUID
+	StartDate *time.Time
+	EndDate *time.Time
+	ClearEndDate bool
+	BillingPeriod *BillingPeriod
+	Currency *string
+}
+
+func (p SubscriptionPatch) IsEmpty() bool {
+	return p.ServiceName == nil && p.Price == nil && p.UserID == nil && p.StartDate == nil &&
+		p.EndDate == nil && !p.ClearEndDate && p.BillingPeriod == nil && p.Currency == nil
+}
+
+// Apply returns a copy of s with the patch applied.
+func (p SubscriptionPatch) Apply(s Subscription) Subscription {
+	if p.ServiceName != nil {
+		s.ServiceName = *p.ServiceName
+	}
+	if p.Price != nil {
+		s.Price = *p.Price
+	}
+	if p.UserID != nil {
+		s.UserID = *p.UserID
+	}
+	if p.StartDate != nil {
+		s.StartDate = *p.StartDate
+	}
+	if p.EndDate != nil {
+		s.EndDate = p.EndDate
+	}
+	if p.ClearEndDate {
+		s.EndDate = nil
+	}
+	if p.BillingPeriod != nil {
+		s.BillingPeriod = *p.BillingPeriod
+	}
+	if p.Currency != nil {
+		s.Currency = *p.Currency
+	}
+	return s
+}
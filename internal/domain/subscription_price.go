@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SubscriptionPrice is a price that applies to a subscription from the
+// month of EffectiveFrom until the next price change.
+type SubscriptionPrice struct {
+	SubscriptionID uuid.UUID
+	Price int
+	EffectiveFrom time.Time
+}
+
+
+// PriceAt returns the price in effect on date given the initial price of a
+// subscription and its price changes ordered by EffectiveFrom.
+func PriceAt(initial int, changes []SubscriptionPrice, date time.Time) int {
+	price := initial
+	for _, change := range changes {
+		if change.EffectiveFrom.After(date) {
+			break
+		}
+		price = change.Price
+	}
+	return price
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPriceAt(t *testing.T) {
+	month := func(year int, m time.Month) time.Time {
+		return time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+	}
+	changes := []SubscriptionPrice{
+		{Price: 500, EffectiveFrom: month(2025, time.March)},
+		{Price: 650, EffectiveFrom: month(2025, time.September)},
+	}
+
+	tests := []struct {
+		name    string
+		changes []SubscriptionPrice
+		date    time.Time
+		want    int
+	}{
+		{name: "no changes", date: month(2025, time.May), want: 400},
+		{name: "before the first change", changes: changes, date: month(2025, time.February), want: 400},
+		{name: "month of a change", changes: changes, date: month(2025, time.March), want: 500},
+		{name: "between changes", changes: changes, date: time.Date(2025, time.August, 31, 0, 0, 0, 0, time.UTC), want: 500},
+		{name: "after the last change", changes: changes, date: month(2026, time.January), want: 650},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PriceAt(400, tt.changes, tt.date); got != tt.want {
+				t.Errorf("PriceAt() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
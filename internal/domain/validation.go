@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"strings"
+)
+
+// FieldError describes why a single field of a request is invalid.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// FieldErrors collects field errors while validating an object.
+type FieldErrors []FieldError
+
+func (e *FieldErrors) Add(field string, message string) {
+	*e = append(*e, FieldError{Field: field, Message: message})
+}
+
+// Err returns a validation error carrying the collected field errors,
+// or nil when there are none.
+func (e FieldErrors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+
+	messages := make([]string, 0, len(e))
+	for _, fieldErr := range e {
+		messages = append(messages, fieldErr.Field+": "+fieldErr.Message)
+	}
+
+	return &Error{
+		Kind:    ErrValidation,
+		Code:    "VALIDATION_FAILED",
+		Message: "invalid subscription data: " + strings.Join(messages, "; "),
+		Details: e,
+	}
+}
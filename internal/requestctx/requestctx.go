@@ -0,0 +1,52 @@
+// Package requestctx carries per-request metadata, such as the request ID
+// and the acting user, from the HTTP layer down to the services.
+package requestctx
+
+import (
+	"context"
+
+	"github.com/kgugunava/effective_mobile_golang/internal/domain"
+)
+
+// AnonymousActor is reported for requests that do not identify their actor.
+const AnonymousActor = "anonymous"
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	actorKey
+	principalKey
+)
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID returns the ID of the request ctx belongs to, or "" if none.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+// Actor returns who performs the request ctx belongs to, or AnonymousActor.
+func Actor(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey).(string); ok && actor != "" {
+		return actor
+	}
+	return AnonymousActor
+}
+
+func WithPrincipal(ctx context.Context, principal domain.Principal) context.Context {
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+// Principal returns the authenticated caller of the request ctx belongs to.
+func Principal(ctx context.Context) (domain.Principal, bool) {
+	principal, ok := ctx.Value(principalKey).(domain.Principal)
+	return principal, ok
+}
@@ -0,0 +1,15 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/kgugunava/effective_mobile_golang/internal/adapters/postgres"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key postgres.APIKeyEntity) (postgres.APIKeyEntity, error)
+	GetByHash(ctx context.Context, keyHash string) (postgres.APIKeyEntity, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+}
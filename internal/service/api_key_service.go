@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kgugunava/effective_mobile_golang/internal/adapters/postgres"
+	"github.com/kgugunava/effective_mobile_golang/internal/auth"
+	"github.com/kgugunava/effective_mobile_golang/internal/domain"
+)
+
+type APIKeyService struct {
+	apiKeyRepo APIKeyRepository
+	logger     *slog.Logger
+}
+
+func NewAPIKeyService(repo APIKeyRepository, logger *slog.Logger) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo: repo,
+		logger:     logger,
+	}
+}
+
+// IssueAPIKey creates an API key with the given scopes, valid until
+// expiresAt when it is set. The returned secret is the key itself; it is not
+// stored and cannot be retrieved later.
+func (s *APIKeyService) IssueAPIKey(ctx context.Context, name string, scopes []domain.Scope, expiresAt *time.Time) (domain.APIKey, string, error) {
+	if strings.TrimSpace(name) == "" {
+		return domain.APIKey{}, "", domain.InvalidInputError("INVALID_NAME", "name must not be empty")
+	}
+	if len(scopes) == 0 {
+		return domain.APIKey{}, "", domain.InvalidInputError("INVALID_SCOPES", "at least one scope is required")
+	}
+	for _, scope := range scopes {
+		if !scope.IsValid() {
+			return domain.APIKey{}, "", domain.InvalidInputError("INVALID_SCOPES", "scope %q must be one of: read, write, admin", scope)
+		}
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return domain.APIKey{}, "", domain.InvalidInputError("INVALID_EXPIRY", "expiry must be in the future")
+	}
+
+	secret, err := auth.GenerateAPIKey()
+	if err != nil {
+		return domain.APIKey{}, "", err
+	}
+
+	key := domain.APIKey{
+		KeyID:     uuid.New(),
+		Name:      name,
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}
+	created, err := s.apiKeyRepo.Create(ctx, transferAPIKeyToPostgresEntity(key, auth.HashAPIKey(secret)))
+	if err != nil {
+		return domain.APIKey{}, "", fmt.Errorf("repository failure: %w", err)
+	}
+
+	s.logger.Info("API key issued",
+		slog.String("key_id", created.KeyID.String()),
+		slog.String("name", created.Name),
+	)
+	return transferAPIKeyEntityToServiceDomain(created), secret, nil
+}
+
+// RevokeAPIKey revokes an API key. Requests using it are rejected from then
+// on.
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	if err := s.apiKeyRepo.Revoke(ctx, id); err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			return domain.NotFoundError("API_KEY_NOT_FOUND", "API key %s does not exist or is already revoked", id)
+		}
+		return fmt.Errorf("repository failure: %w", err)
+	}
+
+	s.logger.Info("API key revoked",
+		slog.String("key_id", id.String()),
+	)
+	return nil
+}
+
+// VerifyAPIKey returns the principal of a valid API key. Unknown, revoked and
+// expired keys yield auth.ErrInvalidAPIKey.
+func (s *APIKeyService) VerifyAPIKey(ctx context.Context, secret string) (domain.Principal, error) {
+	entity, err := s.apiKeyRepo.GetByHash(ctx, auth.HashAPIKey(secret))
+	if err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			return domain.Principal{}, auth.ErrInvalidAPIKey
+		}
+		return domain.Principal{}, fmt.Errorf("repository failure: %w", err)
+	}
+
+	key := transferAPIKeyEntityToServiceDomain(entity)
+	if key.RevokedAt != nil {
+		return domain.Principal{}, fmt.Errorf("%w: key %s is revoked", auth.ErrInvalidAPIKey, key.KeyID)
+	}
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(time.Now()) {
+		return domain.Principal{}, fmt.Errorf("%w: key %s has expired", auth.ErrInvalidAPIKey, key.KeyID)
+	}
+	return domain.Principal{APIKeyID: key.KeyID, Scopes: key.Scopes}, nil
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kgugunava/effective_mobile_golang/internal/adapters/postgres"
+	"github.com/kgugunava/effective_mobile_golang/internal/auth"
+	"github.com/kgugunava/effective_mobile_golang/internal/domain"
+)
+
+// fakeAPIKeyRepository looks keys up by hash. Methods a test does not need
+// panic through the nil embedded interface.
+type fakeAPIKeyRepository struct {
+	APIKeyRepository
+	keys map[string]postgres.APIKeyEntity
+	err  error
+}
+
+func (r fakeAPIKeyRepository) GetByHash(ctx context.Context, keyHash string) (postgres.APIKeyEntity, error) {
+	if r.err != nil {
+		return postgres.APIKeyEntity{}, r.err
+	}
+	key, ok := r.keys[keyHash]
+	if !ok {
+		return postgres.APIKeyEntity{}, postgres.ErrNotFound
+	}
+	return key, nil
+}
+
+func TestVerifyAPIKey(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	keyID := uuid.New()
+
+	tests := []struct {
+		name        string
+		key         *postgres.APIKeyEntity
+		repoErr     error
+		wantInvalid bool
+		wantErr     bool
+		wantScopes  []domain.Scope
+	}{
+		{
+			name:       "valid",
+			key:        &postgres.APIKeyEntity{KeyID: keyID, Scopes: []string{"read", "write"}, ExpiresAt: &future},
+			wantScopes: []domain.Scope{domain.ScopeRead, domain.ScopeWrite},
+		},
+		{
+			name:       "without expiry",
+			key:        &postgres.APIKeyEntity{KeyID: keyID, Scopes: []string{"admin"}},
+			wantScopes: []domain.Scope{domain.ScopeAdmin},
+		},
+		{name: "unknown", wantInvalid: true},
+		{name: "revoked", key: &postgres.APIKeyEntity{KeyID: keyID, Scopes: []string{"read"}, RevokedAt: &past}, wantInvalid: true},
+		{name: "expired", key: &postgres.APIKeyEntity{KeyID: keyID, Scopes: []string{"read"}, ExpiresAt: &past}, wantInvalid: true},
+		{name: "repository failure", repoErr: errors.New("connection refused"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := "secret"
+			repo := fakeAPIKeyRepository{keys: map[string]postgres.APIKeyEntity{}, err: tt.repoErr}
+			if tt.key != nil {
+				repo.keys[auth.HashAPIKey(secret)] = *tt.key
+			}
+
+			principal, err := NewAPIKeyService(repo, slog.New(slog.DiscardHandler)).VerifyAPIKey(context.Background(), secret)
+			switch {
+			case tt.wantInvalid:
+				if !errors.Is(err, auth.ErrInvalidAPIKey) {
+					t.Fatalf("VerifyAPIKey() error = %v, want %v", err, auth.ErrInvalidAPIKey)
+				}
+				return
+			case tt.wantErr:
+				if err == nil || errors.Is(err, auth.ErrInvalidAPIKey) {
+					t.Fatalf("VerifyAPIKey() error = %v, want a repository failure", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyAPIKey() error = %v", err)
+			}
+			if principal.APIKeyID != keyID || !principal.IsAPIKey() {
+				t.Errorf("key ID = %s, want %s", principal.APIKeyID, keyID)
+			}
+			if !slices.Equal(principal.Scopes, tt.wantScopes) {
+				t.Errorf("scopes = %v, want %v", principal.Scopes, tt.wantScopes)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package service
+
+import (
+	"context"
+
+	"github.com/kgugunava/effective_mobile_golang/internal/adapters/postgres"
+)
+
+type AuditRepository interface {
+	Record(ctx context.Context, entries []postgres.AuditEntity) error
+	List(ctx context.Context, filter postgres.AuditFilter) ([]postgres.AuditEntity, error)
+}
\ No newline at end of file
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kgugunava/effective_mobile_golang/internal/adapters/postgres"
+	"github.com/kgugunava/effective_mobile_golang/internal/domain"
+)
+
+// batchItemIndex returns the index of the batch item a repository error is
+// attributed to.
+func batchItemIndex(err error) (int, bool) {
+	var itemErr *postgres.BatchItemError
+	if errors.As(err, &itemErr) {
+		return itemErr.Index, true
+	}
+	return 0, false
+}
+
+// wrapRepositoryError translates repository errors into domain errors.
+// Errors the client cannot act upon are returned wrapped as they are.
+func wrapRepositoryError(err error) error {
+	switch {
+	case errors.Is(err, postgres.ErrNotFound):
+		return domain.NotFoundError("SUBSCRIPTION_NOT_FOUND", "subscription not found")
+	case errors.Is(err, postgres.ErrDuplicate):
+		return domain.ConflictError("SUBSCRIPTION_CONFLICT", "subscription already exists")
+	case errors.Is(err, postgres.ErrConstraint):
+		return domain.ValidationError("CONSTRAINT_VIOLATION", "subscription data violates a constraint")
+	case errors.Is(err, postgres.ErrVersionMismatch):
+		return domain.PreconditionFailedError("VERSION_MISMATCH", "subscription was modified, version does not match If-Match")
+	case errors.Is(err, postgres.ErrIdempotencyKeyReused):
+		return domain.ValidationError("IDEMPOTENCY_KEY_REUSED", "idempotency key was already used with a different request")
+	case errors.Is(err, postgres.ErrMissingExchangeRate):
+		return domain.ValidationError("EXCHANGE_RATE_MISSING", "%s", err.Error())
+	}
+	return fmt.Errorf("repository failure: %w", err)
+}
@@ -0,0 +1,11 @@
+package service
+
+import (
+	"context"
+
+	"github.com/kgugunava/effective_mobile_golang/internal/adapters/postgres"
+)
+
+type ExchangeRateRepository interface {
+	UpsertRates(ctx context.Context, rates []postgres.ExchangeRateEntity) error
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kgugunava/effective_mobile_golang/internal/domain"
+)
+
+type ExchangeRateService struct {
+	exchangeRateRepo ExchangeRateRepository
+	logger           *slog.Logger
+}
+
+func NewExchangeRateService(repo ExchangeRateRepository, logger *slog.Logger) *ExchangeRateService {
+	return &ExchangeRateService{
+		exchangeRateRepo: repo,
+		logger:           logger,
+	}
+}
+
+type exchangeRateRecord struct {
+	Currency      string  `json:"currency"`
+	Rate          float64 `json:"rate"`
+	EffectiveFrom string  `json:"effective_from"`
+}
+
+// LoadRatesFromFile reads exchange rates from a .json or .csv file and
+// stores them, replacing rates with the same currency and effective date.
+// CSV files must have a currency,rate,effective_from header.
+func (s *ExchangeRateService) LoadRatesFromFile(ctx context.Context, path string) (int, error) {
+	s.logger.Debug("loading exchange rates", slog.String("path", path))
+
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open exchange rates file: %w", err)
+	}
+	defer file.Close()
+
+	var records []exchangeRateRecord
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		if err := json.NewDecoder(file).Decode(&records); err != nil {
+			return 0, fmt.Errorf("failed to decode exchange rates JSON: %w", err)
+		}
+	case ".csv":
+		if records, err = readExchangeRatesCSV(file); err != nil {
+			return 0, err
+		}
+	default:
+		return 0, fmt.Errorf("unsupported exchange rates file format: %s", path)
+	}
+
+	rates := make([]domain.ExchangeRate, 0, len(records))
+	for i, record := range records {
+		rate, err := transferExchangeRateRecordToServiceDomain(record)
+		if err != nil {
+			return 0, fmt.Errorf("invalid exchange rate #%d: %w", i+1, err)
+		}
+		rates = append(rates, rate)
+	}
+
+	if err := s.exchangeRateRepo.UpsertRates(ctx, transferExchangeRatesToPostgresEntities(rates)); err != nil {
+		s.logger.Error("failed to store exchange rates in repository",
+			slog.String("path", path),
+			slog.Any("error", err),
+		)
+		return 0, err
+	}
+
+	s.logger.Info("exchange rates loaded",
+		slog.String("path", path),
+		slog.Int("count", len(rates)),
+	)
+	return len(rates), nil
+}
+
+func readExchangeRatesCSV(r io.Reader) ([]exchangeRateRecord, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read exchange rates CSV: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	columns := make(map[string]int)
+	for i, name := range rows[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"currency", "rate", "effective_from"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("exchange rates CSV is missing column %q", name)
+		}
+	}
+
+	var records []exchangeRateRecord
+	for line, row := range rows[1:] {
+		rate, err := strconv.ParseFloat(row[columns["rate"]], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate on line %d: %w", line+2, err)
+		}
+		records = append(records, exchangeRateRecord{
+			Currency:      row[columns["currency"]],
+			Rate:          rate,
+			EffectiveFrom: row[columns["effective_from"]],
+		})
+	}
+
+	return records, nil
+}
+
+func transferExchangeRateRecordToServiceDomain(record exchangeRateRecord) (domain.ExchangeRate, error) {
+	currency := strings.ToUpper(strings.TrimSpace(record.Currency))
+	if !domain.IsKnownCurrency(currency) {
+		return domain.ExchangeRate{}, fmt.Errorf("unknown currency %q", record.Currency)
+	}
+	if record.Rate <= 0 {
+		return domain.ExchangeRate{}, fmt.Errorf("rate must be > 0")
+	}
+
+	effectiveFrom, err := time.Parse("2006-01-02", record.EffectiveFrom)
+	if err != nil {
+		if effectiveFrom, err = time.Parse("01-2006", record.EffectiveFrom); err != nil {
+			return domain.ExchangeRate{}, fmt.Errorf("invalid effective_from %q, expected YYYY-MM-DD or MM-YYYY", record.EffectiveFrom)
+		}
+	}
+
+	return domain.ExchangeRate{
+		Currency:      currency,
+		Rate:          record.Rate,
+		EffectiveFrom: effectiveFrom,
+	}, nil
+}
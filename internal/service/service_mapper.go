@@ -1,6 +1,12 @@
 package service
 
 import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
 	"github.com/kgugunava/effective_mobile_golang/internal/adapters/postgres"
 	"github.com/kgugunava/effective_mobile_golang/internal/domain"
 )
@@ -12,6 +18,8 @@ func transferServiceDomainToPostgresEntity(subscription domain.Subscription) pos
 		ServiceName: subscription.ServiceName,
 		Price:       subscription.Price,
 		StartDate:   subscription.StartDate,
+		BillingPeriod: string(subscription.BillingPeriod),
+		Currency: subscription.Currency,
 	}
 
 	if subscription.EndDate != nil {
@@ -22,13 +30,48 @@ func transferServiceDomainToPostgresEntity(subscription domain.Subscription) pos
 
 }
 
+func transferIdempotencyKeyToPostgresEntity(userID uuid.UUID, key string, requestHash string, expiresAt time.Time) postgres.IdempotencyKeyEntity {
+	return postgres.IdempotencyKeyEntity{
+		UserID: userID,
+		Key: key,
+		RequestHash: requestHash,
+		ExpiresAt: expiresAt,
+	}
+}
+
+func transferPauseToPostgresEntity(pause domain.SubscriptionPause) postgres.SubscriptionPauseEntity {
+	return postgres.SubscriptionPauseEntity{
+		SubscriptionID: pause.SubscriptionID,
+		StartMonth: pause.StartMonth,
+		EndMonth: pause.EndMonth,
+	}
+}
+
+func transferPauseEntitiesToServiceDomain(entities []postgres.SubscriptionPauseEntity) []domain.SubscriptionPause {
+	pauses := make([]domain.SubscriptionPause, 0, len(entities))
+	for _, entity := range entities {
+		pauses = append(pauses, domain.SubscriptionPause{
+			SubscriptionID: entity.SubscriptionID,
+			StartMonth: entity.StartMonth,
+			EndMonth: entity.EndMonth,
+		})
+	}
+	return pauses
+}
+
 func transferPostgresEntityToServiceDomain(entity postgres.SubscriptionEntity) domain.Subscription {
 	domain := domain.Subscription{
 		SubscriptionID: entity.SubscriptionID,
 		ServiceName: entity.ServiceName,
-		Price: entity.Price,
+		Price: entity.CurrentPrice,
 		UserID: entity.UserID,
 		StartDate: entity.StartDate,
+		BillingPeriod: domain.BillingPeriod(entity.BillingPeriod),
+		Currency: entity.Currency,
+		Status: domain.SubscriptionStatus(entity.Status),
+		Version: entity.Version,
+		UpdatedAt: entity.UpdatedAt,
+		DeletedAt: entity.DeletedAt,
 	}
 
 	if entity.EndDate != nil {
@@ -45,12 +88,314 @@ func transferPostgresEntityListsToServiceDomainList(entities []postgres.Subscrip
 		domain := domain.Subscription{
 			SubscriptionID: entity.SubscriptionID,
 			ServiceName: entity.ServiceName,
-			Price: entity.Price,
+			Price: entity.CurrentPrice,
 			UserID: entity.UserID,
 			StartDate: entity.StartDate,
+			EndDate: entity.EndDate,
+			BillingPeriod: domain.BillingPeriod(entity.BillingPeriod),
+			Currency: entity.Currency,
+			Status: domain.SubscriptionStatus(entity.Status),
+			Version: entity.Version,
+			UpdatedAt: entity.UpdatedAt,
+			DeletedAt: entity.DeletedAt,
 		}
 		domainSubscriptions = append(domainSubscriptions, domain)
 	}
 
 	return domainSubscriptions
-}
\ No newline at end of file
+}
+
+func transferMonthlySpendEntitiesToServiceDomain(entities []postgres.MonthlyServiceSpendEntity, startDate time.Time, endDate time.Time) []domain.MonthlySpend {
+	first := time.Date(startDate.Year(), startDate.Month(), 1, 0, 0, 0, 0, time.UTC)
+	last := time.Date(endDate.Year(), endDate.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	monthlySpend := []domain.MonthlySpend{}
+	index := make(map[time.Time]int)
+	for month := first; !month.After(last); month = month.AddDate(0, 1, 0) {
+		index[month] = len(monthlySpend)
+		monthlySpend = append(monthlySpend, domain.MonthlySpend{
+			Month:    month,
+			Services: []domain.ServiceSpend{},
+		})
+	}
+
+	for _, entity := range entities {
+		month := time.Date(entity.Month.Year(), entity.Month.Month(), 1, 0, 0, 0, 0, time.UTC)
+		i, ok := index[month]
+		if !ok {
+			continue
+		}
+		monthlySpend[i].Total += entity.Total
+		monthlySpend[i].Services = append(monthlySpend[i].Services, domain.ServiceSpend{
+			ServiceName: entity.ServiceName,
+			Total:       entity.Total,
+		})
+	}
+
+	return monthlySpend
+}
+
+
+func transferCostQueryToPostgresFilter(query domain.CostQuery) postgres.CostFilter {
+	return postgres.CostFilter{
+		UserID:      query.UserID,
+		ServiceName: query.ServiceName,
+		StartDate:   query.StartDate,
+		EndDate:     query.EndDate,
+		Basis:       string(query.Basis),
+		Currency:    query.Currency,
+	}
+}
+
+func transferExchangeRatesToPostgresEntities(rates []domain.ExchangeRate) []postgres.ExchangeRateEntity {
+	entities := make([]postgres.ExchangeRateEntity, 0, len(rates))
+
+	for _, rate := range rates {
+		entities = append(entities, postgres.ExchangeRateEntity{
+			Currency:      rate.Currency,
+			Rate:          rate.Rate,
+			EffectiveFrom: rate.EffectiveFrom,
+		})
+	}
+
+	return entities
+}
+
+
+func transferServiceDomainPriceToPostgresEntity(price domain.SubscriptionPrice) postgres.SubscriptionPriceEntity {
+	return postgres.SubscriptionPriceEntity{
+		SubscriptionID: price.SubscriptionID,
+		Price:          price.Price,
+		EffectiveFrom:  price.EffectiveFrom,
+	}
+}
+
+// transferPriceHistoryToServiceDomain returns the full price history of a
+// subscription: its initial price from start_date followed by every change.
+func transferPriceHistoryToServiceDomain(subscription postgres.SubscriptionEntity, entities []postgres.SubscriptionPriceEntity) []domain.SubscriptionPrice {
+	startMonth := time.Date(subscription.StartDate.Year(), subscription.StartDate.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	prices := []domain.SubscriptionPrice{}
+	if len(entities) == 0 || entities[0].EffectiveFrom.After(startMonth) {
+		prices = append(prices, domain.SubscriptionPrice{
+			SubscriptionID: subscription.SubscriptionID,
+			Price:          subscription.Price,
+			EffectiveFrom:  subscription.StartDate,
+		})
+	}
+
+	for _, entity := range entities {
+		prices = append(prices, domain.SubscriptionPrice{
+			SubscriptionID: entity.SubscriptionID,
+			Price:          entity.Price,
+			EffectiveFrom:  entity.EffectiveFrom,
+		})
+	}
+
+	return prices
+}
+
+
+func transferListQueryToPostgresFilter(query domain.SubscriptionListQuery) postgres.SubscriptionListFilter {
+	filter := postgres.SubscriptionListFilter{
+		ServiceName:      query.ServiceName,
+		ServiceNameMatch: string(query.ServiceNameMatch),
+		UserID:           query.UserID,
+		StartDate:        query.StartDate,
+		EndDate:          query.EndDate,
+		PeriodMode:       string(query.PeriodMode),
+		ActiveAt:         query.ActiveAt,
+		MinPrice:         query.MinPrice,
+		MaxPrice:         query.MaxPrice,
+		HasEndDate:       query.HasEndDate,
+		Deleted:          query.Deleted,
+		SortBy:           string(query.SortBy),
+		Order:            string(query.Order),
+		Limit:            query.Limit,
+	}
+
+	if query.After != nil {
+		filter.After = &postgres.ListCursor{
+			SortValue:      query.After.Value,
+			SubscriptionID: query.After.SubscriptionID,
+		}
+	}
+
+	return filter
+}
+
+
+// transferPatchToPostgresChanges lists the columns changed by a patch.
+// A cleared end date is stored as NULL.
+func transferPatchToPostgresChanges(patch domain.SubscriptionPatch) map[string]interface{} {
+	changes := make(map[string]interface{})
+
+	if patch.ServiceName != nil {
+		changes["service_name"] = *patch.ServiceName
+	}
+	if patch.UserID != nil {
+		changes["user_id"] = *patch.UserID
+	}
+	if patch.StartDate != nil {
+		changes["start_date"] = *patch.StartDate
+	}
+	if patch.EndDate != nil {
+		changes["end_date"] = *patch.EndDate
+	}
+	if patch.ClearEndDate {
+		changes["end_date"] = nil
+	}
+	if patch.BillingPeriod != nil {
+		changes["billing_period"] = string(*patch.BillingPeriod)
+	}
+	if patch.Currency != nil {
+		changes["currency"] = *patch.Currency
+	}
+
+	return changes
+}
+
+
+// auditSnapshot is the JSON form of a subscription stored in the audit log.
+type auditSnapshot struct {
+	SubscriptionID uuid.UUID `json:"subscription_id"`
+	ServiceName string `json:"service_name"`
+	Price int `json:"price"`
+	UserID uuid.UUID `json:"user_id"`
+	StartDate time.Time `json:"start_date"`
+	EndDate *time.Time `json:"end_date"`
+	BillingPeriod string `json:"billing_period"`
+	Currency string `json:"currency"`
+	Status string `json:"status"`
+	Version int `json:"version"`
+	UpdatedAt time.Time `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+func transferSubscriptionToAuditSnapshot(subscription *domain.Subscription) ([]byte, error) {
+	if subscription == nil {
+		return nil, nil
+	}
+	return json.Marshal(auditSnapshot{
+		SubscriptionID: subscription.SubscriptionID,
+		ServiceName: subscription.ServiceName,
+		Price: subscription.Price,
+		UserID: subscription.UserID,
+		StartDate: subscription.StartDate,
+		EndDate: subscription.EndDate,
+		BillingPeriod: string(subscription.BillingPeriod),
+		Currency: subscription.Currency,
+		Status: string(subscription.Status),
+		Version: subscription.Version,
+		UpdatedAt: subscription.UpdatedAt,
+		DeletedAt: subscription.DeletedAt,
+	})
+}
+
+func transferAuditSnapshotToServiceDomain(data []byte) (*domain.Subscription, error) {
+	if data == nil {
+		return nil, nil
+	}
+	var snapshot auditSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode audit snapshot: %w", err)
+	}
+	return &domain.Subscription{
+		SubscriptionID: snapshot.SubscriptionID,
+		ServiceName: snapshot.ServiceName,
+		Price: snapshot.Price,
+		UserID: snapshot.UserID,
+		StartDate: snapshot.StartDate,
+		EndDate: snapshot.EndDate,
+		BillingPeriod: domain.BillingPeriod(snapshot.BillingPeriod),
+		Currency: snapshot.Currency,
+		Status: domain.SubscriptionStatus(snapshot.Status),
+		Version: snapshot.Version,
+		UpdatedAt: snapshot.UpdatedAt,
+		DeletedAt: snapshot.DeletedAt,
+	}, nil
+}
+
+func transferAuditEntryToPostgresEntity(entry domain.AuditEntry) (postgres.AuditEntity, error) {
+	before, err := transferSubscriptionToAuditSnapshot(entry.Before)
+	if err != nil {
+		return postgres.AuditEntity{}, err
+	}
+	after, err := transferSubscriptionToAuditSnapshot(entry.After)
+	if err != nil {
+		return postgres.AuditEntity{}, err
+	}
+	return postgres.AuditEntity{
+		SubscriptionID: entry.SubscriptionID,
+		UserID: entry.UserID,
+		Action: string(entry.Action),
+		Actor: entry.Actor,
+		RequestID: entry.RequestID,
+		Before: before,
+		After: after,
+	}, nil
+}
+
+func transferAuditEntitiesToServiceDomain(entities []postgres.AuditEntity) ([]domain.AuditEntry, error) {
+	entries := make([]domain.AuditEntry, 0, len(entities))
+	for _, entity := range entities {
+		before, err := transferAuditSnapshotToServiceDomain(entity.Before)
+		if err != nil {
+			return nil, err
+		}
+		after, err := transferAuditSnapshotToServiceDomain(entity.After)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, domain.AuditEntry{
+			AuditID: entity.AuditID,
+			SubscriptionID: entity.SubscriptionID,
+			UserID: entity.UserID,
+			Action: domain.AuditAction(entity.Action),
+			Actor: entity.Actor,
+			RequestID: entity.RequestID,
+			Before: before,
+			After: after,
+			CreatedAt: entity.CreatedAt,
+		})
+	}
+	return entries, nil
+}
+
+func transferAuditQueryToPostgresFilter(query domain.AuditQuery) postgres.AuditFilter {
+	return postgres.AuditFilter{
+		SubscriptionID: query.SubscriptionID,
+		UserID: query.UserID,
+		Before: query.Before,
+		Limit: query.Limit,
+	}
+}
+
+func transferAPIKeyToPostgresEntity(key domain.APIKey, keyHash string) postgres.APIKeyEntity {
+	scopes := make([]string, len(key.Scopes))
+	for i, scope := range key.Scopes {
+		scopes[i] = string(scope)
+	}
+	return postgres.APIKeyEntity{
+		KeyID: key.KeyID,
+		Name: key.Name,
+		KeyHash: keyHash,
+		Scopes: scopes,
+		ExpiresAt: key.ExpiresAt,
+	}
+}
+
+func transferAPIKeyEntityToServiceDomain(entity postgres.APIKeyEntity) domain.APIKey {
+	scopes := make([]domain.Scope, len(entity.Scopes))
+	for i, scope := range entity.Scopes {
+		scopes[i] = domain.Scope(scope)
+	}
+	return domain.APIKey{
+		KeyID: entity.KeyID,
+		Name: entity.Name,
+		Scopes: scopes,
+		ExpiresAt: entity.ExpiresAt,
+		CreatedAt: entity.CreatedAt,
+		RevokedAt: entity.RevokedAt,
+	}
+}
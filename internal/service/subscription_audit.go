@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/kgugunava/effective_mobile_golang/internal/adapters/postgres"
+	"github.com/kgugunava/effective_mobile_golang/internal/domain"
+	"github.com/kgugunava/effective_mobile_golang/internal/requestctx"
+)
+
+// auditChange builds the audit entry of a change made on behalf of the
+// request ctx belongs to.
+func auditChange(ctx context.Context, action domain.AuditAction, before *domain.Subscription, after *domain.Subscription) domain.AuditEntry {
+	entry := domain.AuditEntry{
+		Action:    action,
+		Actor:     requestctx.Actor(ctx),
+		RequestID: requestctx.RequestID(ctx),
+		Before:    before,
+		After:     after,
+	}
+	latest := after
+	if latest == nil {
+		latest = before
+	}
+	entry.SubscriptionID = latest.SubscriptionID
+	entry.UserID = latest.UserID
+	return entry
+}
+
+// recordAudit stores audit entries for changes made in the transaction ctx
+// carries. A failure has to roll the changes back, so that none of them is
+// committed without its entry.
+func (s *SubscriptionService) recordAudit(ctx context.Context, entries ...domain.AuditEntry) error {
+	entities := make([]postgres.AuditEntity, 0, len(entries))
+	for _, entry := range entries {
+		entity, err := transferAuditEntryToPostgresEntity(entry)
+		if err != nil {
+			s.logger.Error("failed to encode audit entry",
+				slog.String("subscription_id", entry.SubscriptionID.String()),
+				slog.Any("error", err),
+			)
+			return err
+		}
+		entities = append(entities, entity)
+	}
+
+	if err := s.auditRepo.Record(ctx, entities); err != nil {
+		s.logger.Error("failed to record audit entries",
+			slog.Int("count", len(entities)),
+			slog.Any("error", err),
+		)
+		return err
+	}
+	return nil
+}
+
+// GetAuditHistory returns the history of a subscription or of a user, which
+// the caller must be allowed to read.
+func (s *SubscriptionService) GetAuditHistory(ctx context.Context, query domain.AuditQuery) (domain.AuditPage, error) {
+	var err error
+	switch {
+	case query.SubscriptionID != nil:
+		err = s.authorizeSubscription(ctx, *query.SubscriptionID, authorizeRead)
+	case query.UserID != nil:
+		err = authorizeRead(ctx, *query.UserID)
+	default:
+		err = authorizeAdmin(ctx)
+	}
+	if err != nil {
+		return domain.AuditPage{}, err
+	}
+	return s.auditHistory(ctx, query)
+}
+
+// GetAllAuditHistory returns the history of every subscription, narrowed by
+// the query when it selects a subscription or a user. It is restricted to
+// admins.
+func (s *SubscriptionService) GetAllAuditHistory(ctx context.Context, query domain.AuditQuery) (domain.AuditPage, error) {
+	if err := authorizeAdmin(ctx); err != nil {
+		return domain.AuditPage{}, err
+	}
+	return s.auditHistory(ctx, query)
+}
+
+func (s *SubscriptionService) auditHistory(ctx context.Context, query domain.AuditQuery) (domain.AuditPage, error) {
+	filter := transferAuditQueryToPostgresFilter(query)
+	// One extra entry tells whether there is a next page.
+	filter.Limit++
+
+	entities, err := s.auditRepo.List(ctx, filter)
+	if err != nil {
+		s.logger.Error("failed to get audit history in repository",
+			slog.Any("error", err),
+		)
+		return domain.AuditPage{}, wrapRepositoryError(err)
+	}
+
+	entries, err := transferAuditEntitiesToServiceDomain(entities)
+	if err != nil {
+		return domain.AuditPage{}, err
+	}
+
+	page := domain.AuditPage{Entries: entries}
+	if len(entries) > query.Limit {
+		page.Entries = entries[:query.Limit]
+		next := page.Entries[len(page.Entries)-1].AuditID
+		page.NextBefore = &next
+	}
+	return page, nil
+}
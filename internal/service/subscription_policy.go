@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/kgugunava/effective_mobile_golang/internal/domain"
+	"github.com/kgugunava/effective_mobile_golang/internal/requestctx"
+)
+
+// principal returns the caller an operation is performed for. Operations
+// without one, such as those started outside of a request, are refused.
+func principal(ctx context.Context) (domain.Principal, error) {
+	p, ok := requestctx.Principal(ctx)
+	if !ok {
+		return domain.Principal{}, domain.ForbiddenError("FORBIDDEN", "the operation requires an authenticated caller")
+	}
+	return p, nil
+}
+
+// authorizeRead checks that the caller may read the subscriptions of userID.
+func authorizeRead(ctx context.Context, userID uuid.UUID) error {
+	p, err := principal(ctx)
+	if err != nil {
+		return err
+	}
+	if !p.CanRead(userID) {
+		return forbiddenUser(userID)
+	}
+	return nil
+}
+
+// authorizeWrite checks that the caller may change the subscriptions of
+// userID.
+func authorizeWrite(ctx context.Context, userID uuid.UUID) error {
+	p, err := principal(ctx)
+	if err != nil {
+		return err
+	}
+	if !p.CanWrite(userID) {
+		return forbiddenUser(userID)
+	}
+	return nil
+}
+
+// authorizeAdmin checks that the caller may use operations spanning all
+// users.
+func authorizeAdmin(ctx context.Context) error {
+	p, err := principal(ctx)
+	if err != nil {
+		return err
+	}
+	if !p.IsAdmin() {
+		return domain.ForbiddenError("ADMIN_REQUIRED", "the operation requires the admin role or scope")
+	}
+	return nil
+}
+
+// authorizeSubscription checks the caller's access to the subscription with
+// the given ID, including one in the trash, using authorize on its owner.
+func (s *SubscriptionService) authorizeSubscription(ctx context.Context, id uuid.UUID, authorize func(context.Context, uuid.UUID) error) error {
+	owner, err := s.subscriptionRepo.GetOwner(ctx, id)
+	if err != nil {
+		return wrapRepositoryError(err)
+	}
+	return authorize(ctx, owner)
+}
+
+func forbiddenUser(userID uuid.UUID) error {
+	return domain.ForbiddenError("FORBIDDEN_USER", "access to subscriptions of user %s is not allowed", userID)
+}
@@ -10,10 +10,29 @@ import (
 )
 
 type SubscriptionRepository interface {
-	Create(ctx context.Context, subscription postgres.SubscriptionEntity) error
+	Create(ctx context.Context, subscription postgres.SubscriptionEntity) (postgres.SubscriptionEntity, error)
+	CreateIdempotent(ctx context.Context, subscription postgres.SubscriptionEntity, key postgres.IdempotencyKeyEntity) (postgres.SubscriptionEntity, bool, error)
+	CreateBatch(ctx context.Context, subscriptions []postgres.SubscriptionEntity) ([]postgres.SubscriptionEntity, error)
 	GetByID(ctx context.Context, id uuid.UUID) (postgres.SubscriptionEntity, error)
-	UpdatePut(ctx context.Context, sub postgres.SubscriptionEntity, id uuid.UUID) (postgres.SubscriptionEntity, error)
-	UpdatePatch(ctx context.Context, id uuid.UUID, changes map[string]interface{}) (postgres.SubscriptionEntity, error)
-	DeleteByID(ctx context.Context, id uuid.UUID) error
-	GetSubscriptionsList(ctx context.Context, serviceName string, userID uuid.UUID, startDate time.Time, endDate time.Time) ([]postgres.SubscriptionEntity, error) 
+	GetByIDForUpdate(ctx context.Context, id uuid.UUID) (postgres.SubscriptionEntity, error)
+	GetOwner(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
+	UpdatePut(ctx context.Context, sub postgres.SubscriptionEntity, id uuid.UUID, expectedVersions []int) (postgres.SubscriptionEntity, error)
+	UpdatePatch(ctx context.Context, id uuid.UUID, changes map[string]interface{}, expectedVersions []int) (postgres.SubscriptionEntity, error)
+	DeleteByID(ctx context.Context, id uuid.UUID, expectedVersions []int) error
+	Cancel(ctx context.Context, id uuid.UUID, endDate time.Time, expectedVersions []int) (postgres.SubscriptionEntity, error)
+	Pause(ctx context.Context, pause postgres.SubscriptionPauseEntity, expectedVersions []int) (postgres.SubscriptionEntity, error)
+	Resume(ctx context.Context, id uuid.UUID, resumeMonth time.Time, expectedVersions []int) (postgres.SubscriptionEntity, error)
+	Restore(ctx context.Context, id uuid.UUID) (postgres.SubscriptionEntity, error)
+	PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error)
+	GetSubscriptionsList(ctx context.Context, filter postgres.SubscriptionListFilter) ([]postgres.SubscriptionEntity, *postgres.ListCursor, error)
+	StreamSubscriptions(ctx context.Context, filter postgres.SubscriptionListFilter, fn func(postgres.SubscriptionEntity) error) error
+	CountSubscriptions(ctx context.Context, filter postgres.SubscriptionListFilter) (int64, error)
+	GetTotalCost(ctx context.Context, filter postgres.CostFilter) (int64, error)
+	BumpVersion(ctx context.Context, id uuid.UUID, expectedVersions []int) (postgres.SubscriptionEntity, error)
+	UpsertPrice(ctx context.Context, price postgres.SubscriptionPriceEntity) error
+	GetPriceHistory(ctx context.Context, id uuid.UUID) ([]postgres.SubscriptionPriceEntity, error)
+	GetActiveSubscriptions(ctx context.Context, userID uuid.UUID, from time.Time, to time.Time) ([]postgres.SubscriptionEntity, error)
+	GetPricesForSubscriptions(ctx context.Context, ids []uuid.UUID) ([]postgres.SubscriptionPriceEntity, error)
+	GetPausesForSubscriptions(ctx context.Context, ids []uuid.UUID) ([]postgres.SubscriptionPauseEntity, error)
+	GetMonthlySpend(ctx context.Context, filter postgres.CostFilter) ([]postgres.MonthlyServiceSpendEntity, error)
 }
\ No newline at end of file
@@ -2,63 +2,245 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/kgugunava/effective_mobile_golang/internal/adapters/postgres"
 	"github.com/kgugunava/effective_mobile_golang/internal/domain"
 )
 
 type SubscriptionService struct {
 	subscriptionRepo SubscriptionRepository
+	auditRepo        AuditRepository
+	txManager        TxManager
 	logger           *slog.Logger
+	idempotencyTTL   time.Duration
+	trashRetention   time.Duration
 }
 
-func NewSubscriptionService(repo SubscriptionRepository, logger *slog.Logger) *SubscriptionService {
+func NewSubscriptionService(repo SubscriptionRepository, auditRepo AuditRepository, txManager TxManager, logger *slog.Logger, idempotencyTTL time.Duration, trashRetention time.Duration) *SubscriptionService {
 	return &SubscriptionService{
 		subscriptionRepo: repo,
+		auditRepo:        auditRepo,
+		txManager:        txManager,
 		logger:           logger,
+		idempotencyTTL:   idempotencyTTL,
+		trashRetention:   trashRetention,
 	}
 }
 
-func isSubscriptionValid(subscription *domain.Subscription) bool {
-	if subscription.Price <= 0 {
-		return false
+// CreateSubscription stores a new subscription. When idempotencyKey is set,
+// a repeated request with the same key and payload for the same user returns
+// the subscription created by the first one instead of creating another.
+func (s *SubscriptionService) CreateSubscription(ctx context.Context, subscription *domain.Subscription, idempotencyKey string) (*domain.Subscription, error) {
+	s.logger.Debug("creating new subscription")
+
+	if err := authorizeWrite(ctx, subscription.UserID); err != nil {
+		return nil, err
 	}
-	if subscription.StartDate.IsZero() {
-		return false
+
+	if subscription.BillingPeriod == "" {
+		subscription.BillingPeriod = domain.BillingPeriodMonthly
 	}
-	if subscription.EndDate != nil && subscription.EndDate.Before(subscription.StartDate) {
-		return false
+	if subscription.Currency == "" {
+		subscription.Currency = domain.BaseCurrency
 	}
-	return true
-}
-
-func (s *SubscriptionService) CreateSubscription(ctx context.Context, subscription *domain.Subscription) (*domain.Subscription, error) {
-	s.logger.Debug("creating new subscription")
-
-	subscription.SubscriptionID = uuid.New()
 
-	if !isSubscriptionValid(subscription) {
+	if err := subscription.Validate(); err != nil {
 		s.logger.Warn("invalid subscription data",
-			slog.String("subscription_id", subscription.SubscriptionID.String()),
+			slog.Any("error", err),
 		)
+		return nil, err
 	}
 
-	if err := s.subscriptionRepo.Create(ctx, transferServiceDomainToPostgresEntity(*subscription)); err != nil {
+	// The hash is taken before an ID is assigned so that retries with the
+	// same payload produce the same hash.
+	requestHash, err := hashCreateRequest(*subscription)
+	if err != nil {
+		return nil, err
+	}
+	subscription.SubscriptionID = uuid.New()
+
+	created := transferServiceDomainToPostgresEntity(*subscription)
+	replayed := false
+	err = s.txManager.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		if idempotencyKey == "" {
+			created, err = s.subscriptionRepo.Create(ctx, created)
+		} else {
+			key := transferIdempotencyKeyToPostgresEntity(subscription.UserID, idempotencyKey, requestHash, time.Now().Add(s.idempotencyTTL))
+			created, replayed, err = s.subscriptionRepo.CreateIdempotent(ctx, created, key)
+		}
+		if err != nil || replayed {
+			return err
+		}
+		result := transferPostgresEntityToServiceDomain(created)
+		return s.recordAudit(ctx, auditChange(ctx, domain.AuditActionCreate, nil, &result))
+	})
+	if err != nil {
 		s.logger.Error("failed to create subscription in repository",
 			slog.String("subscription_id", subscription.SubscriptionID.String()),
 			slog.Any("error", err),
 		)
-		return nil, fmt.Errorf("repository create failed: %w", err)
+		return nil, wrapRepositoryError(err)
 	}
 
+	result := transferPostgresEntityToServiceDomain(created)
 	s.logger.Info("subscription created successfully",
-		slog.String("subscription_id", subscription.SubscriptionID.String()),
+		slog.String("subscription_id", result.SubscriptionID.String()),
+	)
+	return &result, nil
+}
+
+// CreateSubscriptions creates the subscriptions of a bulk request. Items that
+// fail validation or are rejected by the database are reported in their
+// result; in atomic mode any such failure leaves the other items uncreated.
+// An error is returned only when the request could not be processed at all.
+func (s *SubscriptionService) CreateSubscriptions(ctx context.Context, items []domain.BulkCreateItem, mode domain.BulkCreateMode) ([]domain.BulkCreateResult, error) {
+	s.logger.Debug("creating subscriptions in bulk",
+		slog.Int("count", len(items)),
+		slog.String("mode", string(mode)),
 	)
-	return subscription, nil
+
+	caller, err := principal(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results, pending := prepareBulkItems(caller, items)
+	if mode == domain.BulkCreateAtomic && len(pending) != len(items) {
+		skipBulkItems(results, pending)
+		return results, nil
+	}
+
+	// A rejected item aborts the whole batch. In partial mode it is dropped
+	// and the batch is retried with the remaining items.
+	for len(pending) > 0 {
+		entities := make([]postgres.SubscriptionEntity, len(pending))
+		for j, i := range pending {
+			entities[j] = transferServiceDomainToPostgresEntity(*items[i].Subscription)
+		}
+
+		err := s.txManager.InTx(ctx, func(ctx context.Context) error {
+			created, err := s.subscriptionRepo.CreateBatch(ctx, entities)
+			if err != nil {
+				return err
+			}
+			audit := make([]domain.AuditEntry, len(pending))
+			for j, i := range pending {
+				subscription := transferPostgresEntityToServiceDomain(created[j])
+				results[i] = domain.BulkCreateResult{Status: domain.BulkItemCreated, Subscription: &subscription}
+				audit[j] = auditChange(ctx, domain.AuditActionCreate, nil, &subscription)
+			}
+			return s.recordAudit(ctx, audit...)
+		})
+		if err == nil {
+			break
+		}
+
+		j, ok := batchItemIndex(err)
+		if !ok {
+			s.logger.Error("failed to create subscriptions in repository",
+				slog.Int("count", len(entities)),
+				slog.Any("error", err),
+			)
+			return nil, wrapRepositoryError(err)
+		}
+
+		results[pending[j]] = domain.BulkCreateResult{Status: domain.BulkItemFailed, Err: wrapRepositoryError(err)}
+		pending = slices.Delete(pending, j, j+1)
+		if mode == domain.BulkCreateAtomic {
+			skipBulkItems(results, pending)
+			break
+		}
+	}
+
+	s.logger.Info("bulk create finished",
+		slog.Int("count", len(items)),
+		slog.String("mode", string(mode)),
+	)
+	return results, nil
+}
+
+// ImportSubscriptions creates the imported subscriptions all at once. With
+// dryRun set the items are only validated and the valid ones are reported
+// with BulkItemValid.
+func (s *SubscriptionService) ImportSubscriptions(ctx context.Context, items []domain.BulkCreateItem, dryRun bool) ([]domain.BulkCreateResult, error) {
+	if !dryRun {
+		return s.CreateSubscriptions(ctx, items, domain.BulkCreateAtomic)
+	}
+
+	caller, err := principal(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results, pending := prepareBulkItems(caller, items)
+	for _, i := range pending {
+		results[i] = domain.BulkCreateResult{Status: domain.BulkItemValid, Subscription: items[i].Subscription}
+	}
+
+	s.logger.Info("import dry run finished",
+		slog.Int("count", len(items)),
+		slog.Int("valid", len(pending)),
+	)
+	return results, nil
+}
+
+// prepareBulkItems applies the create defaults to every item, assigns IDs
+// and validates them. Items caller may not create fail as well. It returns
+// the results of the failed items and the indexes of the items that can be
+// created.
+func prepareBulkItems(caller domain.Principal, items []domain.BulkCreateItem) ([]domain.BulkCreateResult, []int) {
+	results := make([]domain.BulkCreateResult, len(items))
+	pending := make([]int, 0, len(items))
+	for i, item := range items {
+		err := item.Err
+		if err == nil {
+			subscription := item.Subscription
+			if subscription.BillingPeriod == "" {
+				subscription.BillingPeriod = domain.BillingPeriodMonthly
+			}
+			if subscription.Currency == "" {
+				subscription.Currency = domain.BaseCurrency
+			}
+			subscription.SubscriptionID = uuid.New()
+			err = subscription.Validate()
+			if err == nil && !caller.CanWrite(subscription.UserID) {
+				err = forbiddenUser(subscription.UserID)
+			}
+		}
+		if err != nil {
+			results[i] = domain.BulkCreateResult{Status: domain.BulkItemFailed, Err: err}
+			continue
+		}
+		pending = append(pending, i)
+	}
+	return results, pending
+}
+
+func skipBulkItems(results []domain.BulkCreateResult, indexes []int) {
+	for _, i := range indexes {
+		results[i] = domain.BulkCreateResult{Status: domain.BulkItemSkipped}
+	}
+}
+
+func hashCreateRequest(subscription domain.Subscription) (string, error) {
+	payload, err := json.Marshal(subscription)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode create request: %w", err)
+	}
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:]), nil
 }
 
 func (s *SubscriptionService) GetSubscriptionByID(ctx context.Context, id uuid.UUID) (domain.Subscription, error) {
@@ -72,6 +254,9 @@ func (s *SubscriptionService) GetSubscriptionByID(ctx context.Context, id uuid.U
 			slog.String("subscription_id", id.String()),
 			slog.Any("error", err),
 		)
+		return domain.Subscription{}, wrapRepositoryError(err)
+	}
+	if err := authorizeRead(ctx, subscription.UserID); err != nil {
 		return domain.Subscription{}, err
 	}
 
@@ -81,27 +266,76 @@ func (s *SubscriptionService) GetSubscriptionByID(ctx context.Context, id uuid.U
 	return transferPostgresEntityToServiceDomain(subscription), nil
 }
 
-func (s *SubscriptionService) UpdateSubscriptionPut(ctx context.Context, id uuid.UUID, newSubscription *domain.Subscription) (*domain.Subscription, error) {
+// UpdateSubscriptionPut replaces a subscription. A changed price applies from
+// the current month on, see changeCurrentPrice. A non-nil expectedVersions
+// makes the update conditional on the stored version being one of them.
+func (s *SubscriptionService) UpdateSubscriptionPut(ctx context.Context, id uuid.UUID, newSubscription *domain.Subscription, expectedVersions []int) (*domain.Subscription, error) {
 	s.logger.Debug("updating subscription with PUT",
 		slog.String("subscription_id", id.String()),
 	)
 
+	if newSubscription.BillingPeriod == "" {
+		newSubscription.BillingPeriod = domain.BillingPeriodMonthly
+	}
+	if newSubscription.Currency == "" {
+		newSubscription.Currency = domain.BaseCurrency
+	}
+
 	var updatedSubscription domain.Subscription
+	err := s.txManager.InTx(ctx, func(ctx context.Context) error {
+		current, err := s.subscriptionRepo.GetByIDForUpdate(ctx, id)
+		if err != nil {
+			s.logger.Error("failed to get subscription from repository",
+				slog.String("subscription_id", id.String()),
+				slog.Any("error", err),
+			)
+			return wrapRepositoryError(err)
+		}
+		if err := authorizeWrite(ctx, current.UserID); err != nil {
+			return err
+		}
+
+		// Without a user ID the subscription stays with its owner.
+		if newSubscription.UserID == uuid.Nil {
+			newSubscription.UserID = current.UserID
+		}
+		if err := newSubscription.Validate(); err != nil {
+			s.logger.Warn("invalid subscription data in PUT update",
+				slog.String("subscription_id", id.String()),
+				slog.Any("error", err),
+			)
+			return err
+		}
+		if err := authorizeWrite(ctx, newSubscription.UserID); err != nil {
+			return err
+		}
+		if err := transferPostgresEntityToServiceDomain(current).ValidateDatesChange(*newSubscription); err != nil {
+			return err
+		}
 
-	if isSubscriptionValid(newSubscription) {
-		updatedSubscriptionPostgresEntity, err := s.subscriptionRepo.UpdatePut(ctx, transferServiceDomainToPostgresEntity(*newSubscription), id)
+		updatedSubscriptionPostgresEntity, err := s.subscriptionRepo.UpdatePut(ctx, transferServiceDomainToPostgresEntity(*newSubscription), id, expectedVersions)
 		if err != nil {
 			s.logger.Error("failed to update subscription (PUT) in repository",
 				slog.String("subscription_id", id.String()),
 				slog.Any("error", err),
 			)
-			return nil, err
+			return wrapRepositoryError(err)
+		}
+		if newSubscription.Price != updatedSubscriptionPostgresEntity.CurrentPrice {
+			updatedSubscriptionPostgresEntity, err = s.changeCurrentPrice(ctx, updatedSubscriptionPostgresEntity, newSubscription.Price)
+			if err != nil {
+				return err
+			}
 		}
 		updatedSubscription = transferPostgresEntityToServiceDomain(updatedSubscriptionPostgresEntity)
-	} else {
-		s.logger.Warn("invalid subscription data in PUT update",
-			slog.String("subscription_id", id.String()),
-		)
+		before := transferPostgresEntityToServiceDomain(current)
+		if err := s.recordAudit(ctx, auditChange(ctx, domain.AuditActionUpdate, &before, &updatedSubscription)); err != nil {
+			return wrapRepositoryError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	s.logger.Info("subscription updated (PUT) successfully",
@@ -110,80 +344,678 @@ func (s *SubscriptionService) UpdateSubscriptionPut(ctx context.Context, id uuid
 	return &updatedSubscription, nil
 }
 
-func (s *SubscriptionService) UpdateSubscriptionPatch(ctx context.Context, id uuid.UUID, newSubscription *domain.Subscription) (*domain.Subscription, error) {
+// UpdateSubscriptionPatch applies a partial update. A changed price applies
+// from the current month on, as with PUT. A non-nil
+// expectedVersions makes the update conditional on the stored version being
+// one of them; the version read to validate the patch is then also the one
+// the update is checked against.
+func (s *SubscriptionService) UpdateSubscriptionPatch(ctx context.Context, id uuid.UUID, patch domain.SubscriptionPatch, expectedVersions []int) (*domain.Subscription, error) {
 	s.logger.Debug("updating subscription with PATCH",
 		slog.String("subscription_id", id.String()),
 	)
 
-	changes := make(map[string]interface{})
-	if newSubscription.ServiceName != "" {
-		changes["service_name"] = newSubscription.ServiceName
+	var transferedUpdatedSubscription domain.Subscription
+	changes := transferPatchToPostgresChanges(patch)
+	err := s.txManager.InTx(ctx, func(ctx context.Context) error {
+		current, err := s.subscriptionRepo.GetByIDForUpdate(ctx, id)
+		if err != nil {
+			s.logger.Error("failed to get subscription from repository",
+				slog.String("subscription_id", id.String()),
+				slog.Any("error", err),
+			)
+			return wrapRepositoryError(err)
+		}
+		if err := authorizeWrite(ctx, current.UserID); err != nil {
+			return err
+		}
+		if patch.UserID != nil {
+			if err := authorizeWrite(ctx, *patch.UserID); err != nil {
+				return err
+			}
+		}
+		if expectedVersions != nil {
+			if !slices.Contains(expectedVersions, current.Version) {
+				s.logger.Warn("version mismatch in PATCH update",
+					slog.String("subscription_id", id.String()),
+					slog.Int("version", current.Version),
+				)
+				return domain.PreconditionFailedError("VERSION_MISMATCH", "subscription was modified, version does not match If-Match")
+			}
+			expectedVersions = []int{current.Version}
+		}
+
+		before := transferPostgresEntityToServiceDomain(current)
+		merged := patch.Apply(before)
+
+		if err := merged.Validate(); err != nil {
+			s.logger.Warn("invalid subscription data in PATCH update",
+				slog.String("subscription_id", id.String()),
+				slog.Any("error", err),
+			)
+			return err
+		}
+		if err := before.ValidateDatesChange(merged); err != nil {
+			return err
+		}
+
+		updatedSubscriptionPostgresEntity := current
+		if patch.IsEmpty() {
+			s.logger.Warn("PATCH request with no changes",
+				slog.String("subscription_id", id.String()),
+			)
+		} else {
+			updatedSubscriptionPostgresEntity, err = s.subscriptionRepo.UpdatePatch(ctx, id, changes, expectedVersions)
+			if err != nil {
+				s.logger.Error("failed to patch subscription in repository",
+					slog.String("subscription_id", id.String()),
+					slog.Any("error", err),
+					slog.Any("changes", changes),
+				)
+				return wrapRepositoryError(err)
+			}
+		}
+		if patch.Price != nil && *patch.Price != updatedSubscriptionPostgresEntity.CurrentPrice {
+			updatedSubscriptionPostgresEntity, err = s.changeCurrentPrice(ctx, updatedSubscriptionPostgresEntity, *patch.Price)
+			if err != nil {
+				return err
+			}
+		}
+
+		transferedUpdatedSubscription = transferPostgresEntityToServiceDomain(updatedSubscriptionPostgresEntity)
+		if err := s.recordAudit(ctx, auditChange(ctx, domain.AuditActionPatch, &before, &transferedUpdatedSubscription)); err != nil {
+			return wrapRepositoryError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	if newSubscription.Price != 0 {
-		changes["price"] = newSubscription.Price
+
+	s.logger.Info("subscription patched successfully",
+		slog.String("subscription_id", id.String()),
+		slog.Any("changes", changes),
+	)
+	return &transferedUpdatedSubscription, nil
+}
+
+// changeCurrentPrice makes price the price of a subscription from the
+// current month on, or from its first month when it has not started yet.
+// The months before keep the price they were charged. It returns the
+// subscription as it is afterwards.
+func (s *SubscriptionService) changeCurrentPrice(ctx context.Context, subscription postgres.SubscriptionEntity, price int) (postgres.SubscriptionEntity, error) {
+	now := time.Now()
+	effectiveFrom := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if startMonth := time.Date(subscription.StartDate.Year(), subscription.StartDate.Month(), 1, 0, 0, 0, 0, time.UTC); effectiveFrom.Before(startMonth) {
+		effectiveFrom = startMonth
 	}
-	if newSubscription.EndDate != nil {
-		changes["end_date"] = newSubscription.EndDate
+	if subscription.EndDate != nil && effectiveFrom.After(*subscription.EndDate) {
+		s.logger.Warn("price change after subscription end",
+			slog.String("subscription_id", subscription.SubscriptionID.String()),
+		)
+		return postgres.SubscriptionEntity{}, domain.ValidationError("SUBSCRIPTION_ENDED", "price of a subscription that has ended cannot be changed")
 	}
 
-	if len(changes) == 0 {
-		s.logger.Warn("PATCH request with no changes",
-			slog.String("subscription_id", id.String()),
+	change := domain.SubscriptionPrice{
+		SubscriptionID: subscription.SubscriptionID,
+		Price:          price,
+		EffectiveFrom:  effectiveFrom,
+	}
+	if err := s.subscriptionRepo.UpsertPrice(ctx, transferServiceDomainPriceToPostgresEntity(change)); err != nil {
+		s.logger.Error("failed to change subscription price in repository",
+			slog.String("subscription_id", subscription.SubscriptionID.String()),
+			slog.Any("error", err),
 		)
+		return postgres.SubscriptionEntity{}, wrapRepositoryError(err)
 	}
 
-	updatedSubscriptionPostgresEntity, err := s.subscriptionRepo.UpdatePatch(ctx, id, changes)
+	updated, err := s.subscriptionRepo.GetByID(ctx, subscription.SubscriptionID)
 	if err != nil {
-		s.logger.Error("failed to patch subscription in repository",
-			slog.String("subscription_id", id.String()),
+		s.logger.Error("failed to get subscription from repository",
+			slog.String("subscription_id", subscription.SubscriptionID.String()),
 			slog.Any("error", err),
-			slog.Any("changes", changes),
 		)
-		return nil, err
+		return postgres.SubscriptionEntity{}, wrapRepositoryError(err)
 	}
+	return updated, nil
+}
+
+func (s *SubscriptionService) DeleteSubscriptionByID(ctx context.Context, id uuid.UUID, expectedVersions []int) error {
+	s.logger.Debug("deleting subscription",
+		slog.String("subscription_id", id.String()),
+	)
 
-	transferedUpdatedSubscription := transferPostgresEntityToServiceDomain(updatedSubscriptionPostgresEntity)
+	err := s.txManager.InTx(ctx, func(ctx context.Context) error {
+		current, err := s.subscriptionRepo.GetByIDForUpdate(ctx, id)
+		if err != nil {
+			s.logger.Error("failed to get subscription from repository",
+				slog.String("subscription_id", id.String()),
+				slog.Any("error", err),
+			)
+			return wrapRepositoryError(err)
+		}
+		if err := authorizeWrite(ctx, current.UserID); err != nil {
+			return err
+		}
 
-	s.logger.Info("subscription patched successfully",
+		if err := s.subscriptionRepo.DeleteByID(ctx, id, expectedVersions); err != nil {
+			s.logger.Error("failed to delete subscription in repository",
+				slog.String("subscription_id", id.String()),
+				slog.Any("error", err),
+			)
+			return wrapRepositoryError(err)
+		}
+		before := transferPostgresEntityToServiceDomain(current)
+		if err := s.recordAudit(ctx, auditChange(ctx, domain.AuditActionDelete, &before, nil)); err != nil {
+			return wrapRepositoryError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("subscription deleted successfully",
 		slog.String("subscription_id", id.String()),
-		slog.Any("changes", changes),
 	)
-	return &transferedUpdatedSubscription, nil
+	return nil
 }
 
-func (s *SubscriptionService) DeleteSubscriptionByID(ctx context.Context, id uuid.UUID) error {
-	s.logger.Debug("deleting subscription",
+// CancelSubscription cancels a subscription effective now or at the end of
+// the running billing period.
+func (s *SubscriptionService) CancelSubscription(ctx context.Context, id uuid.UUID, mode domain.CancelMode, expectedVersions []int) (*domain.Subscription, error) {
+	return s.changeLifecycle(ctx, id, domain.LifecycleCancel, expectedVersions, func(ctx context.Context, current domain.Subscription, pauses []domain.SubscriptionPause, versions []int) (postgres.SubscriptionEntity, error) {
+		return s.subscriptionRepo.Cancel(ctx, id, current.CancelEndDate(time.Now(), mode), versions)
+	})
+}
+
+// PauseSubscription pauses a subscription from the month of from through the
+// month of to. The subscription is not charged for these months.
+func (s *SubscriptionService) PauseSubscription(ctx context.Context, id uuid.UUID, from time.Time, to time.Time, expectedVersions []int) (*domain.Subscription, error) {
+	return s.changeLifecycle(ctx, id, domain.LifecyclePause, expectedVersions, func(ctx context.Context, current domain.Subscription, pauses []domain.SubscriptionPause, versions []int) (postgres.SubscriptionEntity, error) {
+		if err := current.ValidatePause(from, to, time.Now(), pauses); err != nil {
+			return postgres.SubscriptionEntity{}, err
+		}
+		pause := domain.SubscriptionPause{
+			SubscriptionID: id,
+			StartMonth:     time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC),
+			EndMonth:       time.Date(to.Year(), to.Month(), 1, 0, 0, 0, 0, time.UTC),
+		}
+		return s.subscriptionRepo.Pause(ctx, transferPauseToPostgresEntity(pause), versions)
+	})
+}
+
+// ResumeSubscription resumes a paused subscription from the current month
+// on and withdraws the pauses scheduled for later months. Paused months
+// before it stay excluded from costs.
+func (s *SubscriptionService) ResumeSubscription(ctx context.Context, id uuid.UUID, expectedVersions []int) (*domain.Subscription, error) {
+	return s.changeLifecycle(ctx, id, domain.LifecycleResume, expectedVersions, func(ctx context.Context, current domain.Subscription, pauses []domain.SubscriptionPause, versions []int) (postgres.SubscriptionEntity, error) {
+		now := time.Now()
+		return s.subscriptionRepo.Resume(ctx, id, time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC), versions)
+	})
+}
+
+// changeLifecycle checks that action is allowed for the current status of
+// the subscription and applies it with change, in the transaction ctx passed
+// to change carries. The subscription is locked from the check on, so a
+// concurrent change cannot bypass the state machine.
+func (s *SubscriptionService) changeLifecycle(ctx context.Context, id uuid.UUID, action domain.LifecycleAction, expectedVersions []int, change func(ctx context.Context, current domain.Subscription, pauses []domain.SubscriptionPause, versions []int) (postgres.SubscriptionEntity, error)) (*domain.Subscription, error) {
+	s.logger.Debug("changing subscription lifecycle",
 		slog.String("subscription_id", id.String()),
+		slog.String("action", string(action)),
 	)
 
-	if err := s.subscriptionRepo.DeleteByID(ctx, id); err != nil {
-		s.logger.Error("failed to delete subscription in repository",
-			slog.String("subscription_id", id.String()),
+	var updated domain.Subscription
+	err := s.txManager.InTx(ctx, func(ctx context.Context) error {
+		currentEntity, err := s.subscriptionRepo.GetByIDForUpdate(ctx, id)
+		if err != nil {
+			s.logger.Error("failed to get subscription from repository",
+				slog.String("subscription_id", id.String()),
+				slog.Any("error", err),
+			)
+			return wrapRepositoryError(err)
+		}
+		current := transferPostgresEntityToServiceDomain(currentEntity)
+		if err := authorizeWrite(ctx, current.UserID); err != nil {
+			return err
+		}
+
+		if expectedVersions != nil && !slices.Contains(expectedVersions, current.Version) {
+			return domain.PreconditionFailedError("VERSION_MISMATCH", "subscription was modified, version does not match If-Match")
+		}
+		pauseEntities, err := s.subscriptionRepo.GetPausesForSubscriptions(ctx, []uuid.UUID{id})
+		if err != nil {
+			s.logger.Error("failed to get subscription pauses from repository",
+				slog.String("subscription_id", id.String()),
+				slog.Any("error", err),
+			)
+			return wrapRepositoryError(err)
+		}
+		pauses := transferPauseEntitiesToServiceDomain(pauseEntities)
+		if _, err := current.TransitionAt(action, pauses, time.Now()); err != nil {
+			s.logger.Warn("illegal lifecycle transition",
+				slog.String("subscription_id", id.String()),
+				slog.String("status", string(current.Status)),
+				slog.String("action", string(action)),
+			)
+			return err
+		}
+
+		updatedEntity, err := change(ctx, current, pauses, []int{current.Version})
+		if err != nil {
+			var domainErr *domain.Error
+			if errors.As(err, &domainErr) {
+				return err
+			}
+			s.logger.Error("failed to change subscription lifecycle in repository",
+				slog.String("subscription_id", id.String()),
+				slog.String("action", string(action)),
+				slog.Any("error", err),
+			)
+			return wrapRepositoryError(err)
+		}
+
+		updated = transferPostgresEntityToServiceDomain(updatedEntity)
+		if err := s.recordAudit(ctx, auditChange(ctx, domain.AuditAction(action), &current, &updated)); err != nil {
+			return wrapRepositoryError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("subscription lifecycle changed",
+		slog.String("subscription_id", id.String()),
+		slog.String("status", string(updated.Status)),
+	)
+	return &updated, nil
+}
+
+func (s *SubscriptionService) RestoreSubscription(ctx context.Context, id uuid.UUID) (*domain.Subscription, error) {
+	s.logger.Debug("restoring subscription",
+		slog.String("subscription_id", id.String()),
+	)
+
+	var result domain.Subscription
+	err := s.txManager.InTx(ctx, func(ctx context.Context) error {
+		if err := s.authorizeSubscription(ctx, id, authorizeWrite); err != nil {
+			return err
+		}
+
+		restored, err := s.subscriptionRepo.Restore(ctx, id)
+		if err != nil {
+			s.logger.Error("failed to restore subscription in repository",
+				slog.String("subscription_id", id.String()),
+				slog.Any("error", err),
+			)
+			return wrapRepositoryError(err)
+		}
+
+		result = transferPostgresEntityToServiceDomain(restored)
+		if err := s.recordAudit(ctx, auditChange(ctx, domain.AuditActionRestore, nil, &result)); err != nil {
+			return wrapRepositoryError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("subscription restored successfully",
+		slog.String("subscription_id", id.String()),
+	)
+	return &result, nil
+}
+
+// PurgeDeletedSubscriptions permanently removes the subscriptions that have
+// been in the trash for longer than the retention period.
+func (s *SubscriptionService) PurgeDeletedSubscriptions(ctx context.Context) (int64, error) {
+	purged, err := s.subscriptionRepo.PurgeDeleted(ctx, time.Now().Add(-s.trashRetention))
+	if err != nil {
+		return 0, wrapRepositoryError(err)
+	}
+	return purged, nil
+}
+
+// RunTrashPurge calls PurgeDeletedSubscriptions every interval until ctx is
+// done.
+func (s *SubscriptionService) RunTrashPurge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.PurgeDeletedSubscriptions(ctx); err != nil {
+			s.logger.Error("failed to purge deleted subscriptions",
+				slog.Any("error", err),
+			)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ListSubscriptions lists the subscriptions of query.UserID.
+func (s *SubscriptionService) ListSubscriptions(ctx context.Context, query domain.SubscriptionListQuery) (domain.SubscriptionPage, error) {
+	if query.UserID == uuid.Nil {
+		return domain.SubscriptionPage{}, domain.InvalidInputError("USER_ID_REQUIRED", "user_id is required")
+	}
+	if err := authorizeRead(ctx, query.UserID); err != nil {
+		return domain.SubscriptionPage{}, err
+	}
+	return s.listSubscriptions(ctx, query)
+}
+
+// ListAllSubscriptions lists the subscriptions of every user, or of
+// query.UserID when it is set. It is restricted to admins.
+func (s *SubscriptionService) ListAllSubscriptions(ctx context.Context, query domain.SubscriptionListQuery) (domain.SubscriptionPage, error) {
+	if err := authorizeAdmin(ctx); err != nil {
+		return domain.SubscriptionPage{}, err
+	}
+	return s.listSubscriptions(ctx, query)
+}
+
+func (s *SubscriptionService) listSubscriptions(ctx context.Context, query domain.SubscriptionListQuery) (domain.SubscriptionPage, error) {
+	filter := transferListQueryToPostgresFilter(query)
+
+	postgresEntities, next, err := s.subscriptionRepo.GetSubscriptionsList(ctx, filter)
+	if err != nil {
+		s.logger.Error("failed to get subscriptions list in repository",
+			slog.String("service_name", query.ServiceName),
+			slog.String("user_id", query.UserID.String()),
+			slog.String("start_date", query.StartDate.String()),
+			slog.String("end_date", query.EndDate.String()),
 			slog.Any("error", err),
 		)
+		return domain.SubscriptionPage{}, wrapRepositoryError(err)
+	}
+
+	totalCount, err := s.subscriptionRepo.CountSubscriptions(ctx, filter)
+	if err != nil {
+		s.logger.Error("failed to count subscriptions in repository",
+			slog.String("user_id", query.UserID.String()),
+			slog.Any("error", err),
+		)
+		return domain.SubscriptionPage{}, wrapRepositoryError(err)
+	}
+
+	page := domain.SubscriptionPage{
+		Subscriptions: transferPostgresEntityListsToServiceDomainList(postgresEntities),
+		TotalCount:    totalCount,
+	}
+	if next != nil {
+		page.NextCursor = &domain.ListCursor{
+			SortBy:         query.SortBy,
+			Order:          query.Order,
+			Value:          next.SortValue,
+			SubscriptionID: next.SubscriptionID,
+		}
+	}
+
+	return page, nil
+}
+
+// ExportSubscriptions calls fn for every subscription matching the query
+// without loading them all into memory.
+func (s *SubscriptionService) ExportSubscriptions(ctx context.Context, query domain.SubscriptionListQuery, fn func(domain.Subscription) error) error {
+	if query.UserID == uuid.Nil {
+		return domain.InvalidInputError("USER_ID_REQUIRED", "user_id is required")
+	}
+	if err := authorizeRead(ctx, query.UserID); err != nil {
 		return err
 	}
 
-	s.logger.Info("subscription deleted successfully",
-		slog.String("subscription_id", id.String()),
-	)
+	err := s.subscriptionRepo.StreamSubscriptions(ctx, transferListQueryToPostgresFilter(query), func(entity postgres.SubscriptionEntity) error {
+		return fn(transferPostgresEntityToServiceDomain(entity))
+	})
+	if err != nil {
+		s.logger.Error("failed to export subscriptions",
+			slog.String("user_id", query.UserID.String()),
+			slog.Any("error", err),
+		)
+		return wrapRepositoryError(err)
+	}
 	return nil
 }
 
-func (s *SubscriptionService) ListSubscriptions(ctx context.Context, serviceName string, userID uuid.UUID, startDate time.Time, endDate time.Time) ([]domain.Subscription, error) {
-	postgresEntities, err := s.subscriptionRepo.GetSubscriptionsList(ctx, serviceName, userID, startDate, endDate)
+func (s *SubscriptionService) GetTotalCost(ctx context.Context, query domain.CostQuery) (int64, error) {
+	if err := authorizeRead(ctx, query.UserID); err != nil {
+		return 0, err
+	}
+
+	total, err := s.subscriptionRepo.GetTotalCost(ctx, transferCostQueryToPostgresFilter(query))
 	if err != nil {
-		s.logger.Error("failed to get subscriptions list in repository",
-			slog.String("service_name", serviceName),
+		s.logger.Error("failed to get total cost in repository",
+			slog.String("service_name", query.ServiceName),
+			slog.String("user_id", query.UserID.String()),
+			slog.String("start_date", query.StartDate.String()),
+			slog.String("end_date", query.EndDate.String()),
+			slog.Any("error", err),
+		)
+		return 0, wrapRepositoryError(err)
+	}
+
+	return total, nil
+}
+
+func (s *SubscriptionService) GetMonthlySpend(ctx context.Context, query domain.CostQuery) ([]domain.MonthlySpend, error) {
+	if err := authorizeRead(ctx, query.UserID); err != nil {
+		return []domain.MonthlySpend{}, err
+	}
+
+	entities, err := s.subscriptionRepo.GetMonthlySpend(ctx, transferCostQueryToPostgresFilter(query))
+	if err != nil {
+		s.logger.Error("failed to get monthly spend in repository",
+			slog.String("service_name", query.ServiceName),
+			slog.String("user_id", query.UserID.String()),
+			slog.String("start_date", query.StartDate.String()),
+			slog.String("end_date", query.EndDate.String()),
+			slog.Any("error", err),
+		)
+		return []domain.MonthlySpend{}, wrapRepositoryError(err)
+	}
+
+	return transferMonthlySpendEntitiesToServiceDomain(entities, query.StartDate, query.EndDate), nil
+}
+
+func (s *SubscriptionService) SchedulePriceChange(ctx context.Context, price domain.SubscriptionPrice, expectedVersions []int) (domain.Subscription, []domain.SubscriptionPrice, error) {
+	s.logger.Debug("scheduling subscription price change",
+		slog.String("subscription_id", price.SubscriptionID.String()),
+	)
+
+	var (
+		after  domain.Subscription
+		prices []domain.SubscriptionPrice
+	)
+	err := s.txManager.InTx(ctx, func(ctx context.Context) error {
+		subscription, err := s.subscriptionRepo.GetByIDForUpdate(ctx, price.SubscriptionID)
+		if err != nil {
+			s.logger.Error("failed to get subscription from repository",
+				slog.String("subscription_id", price.SubscriptionID.String()),
+				slog.Any("error", err),
+			)
+			return wrapRepositoryError(err)
+		}
+		if err := authorizeWrite(ctx, subscription.UserID); err != nil {
+			return err
+		}
+		if expectedVersions != nil && !slices.Contains(expectedVersions, subscription.Version) {
+			s.logger.Warn("version mismatch in price change",
+				slog.String("subscription_id", price.SubscriptionID.String()),
+				slog.Int("version", subscription.Version),
+			)
+			return domain.PreconditionFailedError("VERSION_MISMATCH", "subscription was modified, version does not match If-Match")
+		}
+
+		price.EffectiveFrom = time.Date(price.EffectiveFrom.Year(), price.EffectiveFrom.Month(), 1, 0, 0, 0, 0, time.UTC)
+		startMonth := time.Date(subscription.StartDate.Year(), subscription.StartDate.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+		if price.Price <= 0 {
+			s.logger.Warn("invalid price in price change",
+				slog.String("subscription_id", price.SubscriptionID.String()),
+				slog.Int("price", price.Price),
+			)
+			return domain.ValidationError("INVALID_PRICE", "price must be > 0")
+		}
+		if price.EffectiveFrom.Before(startMonth) {
+			s.logger.Warn("price change before subscription start",
+				slog.String("subscription_id", price.SubscriptionID.String()),
+				slog.Time("effective_from", price.EffectiveFrom),
+			)
+			return domain.ValidationError("INVALID_EFFECTIVE_FROM", "effective_from must not be before start_date")
+		}
+		if subscription.EndDate != nil && price.EffectiveFrom.After(*subscription.EndDate) {
+			s.logger.Warn("price change after subscription end",
+				slog.String("subscription_id", price.SubscriptionID.String()),
+				slog.Time("effective_from", price.EffectiveFrom),
+			)
+			return domain.ValidationError("INVALID_EFFECTIVE_FROM", "effective_from must not be after end_date")
+		}
+
+		if err := s.subscriptionRepo.UpsertPrice(ctx, transferServiceDomainPriceToPostgresEntity(price)); err != nil {
+			s.logger.Error("failed to schedule price change in repository",
+				slog.String("subscription_id", price.SubscriptionID.String()),
+				slog.Any("error", err),
+			)
+			return wrapRepositoryError(err)
+		}
+
+		updated, err := s.subscriptionRepo.GetByID(ctx, price.SubscriptionID)
+		if err != nil {
+			return wrapRepositoryError(err)
+		}
+		// A change of the current price changes the subscription as it is
+		// read, so it gets a new version; a change in a later month does not.
+		if updated.CurrentPrice != subscription.CurrentPrice {
+			if updated, err = s.subscriptionRepo.BumpVersion(ctx, price.SubscriptionID, []int{subscription.Version}); err != nil {
+				return wrapRepositoryError(err)
+			}
+		}
+
+		before := transferPostgresEntityToServiceDomain(subscription)
+		after = transferPostgresEntityToServiceDomain(updated)
+		if err := s.recordAudit(ctx, auditChange(ctx, domain.AuditActionPriceChange, &before, &after)); err != nil {
+			return wrapRepositoryError(err)
+		}
+
+		prices, err = s.GetPriceHistory(ctx, price.SubscriptionID)
+		return err
+	})
+	if err != nil {
+		return domain.Subscription{}, nil, err
+	}
+
+	s.logger.Info("subscription price change scheduled",
+		slog.String("subscription_id", price.SubscriptionID.String()),
+		slog.Time("effective_from", price.EffectiveFrom),
+	)
+	return after, prices, nil
+}
+
+func (s *SubscriptionService) GetPriceHistory(ctx context.Context, id uuid.UUID) ([]domain.SubscriptionPrice, error) {
+	subscription, err := s.subscriptionRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to get subscription from repository",
+			slog.String("subscription_id", id.String()),
+			slog.Any("error", err),
+		)
+		return nil, wrapRepositoryError(err)
+	}
+	if err := authorizeRead(ctx, subscription.UserID); err != nil {
+		return nil, err
+	}
+
+	entities, err := s.subscriptionRepo.GetPriceHistory(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to get price history in repository",
+			slog.String("subscription_id", id.String()),
+			slog.Any("error", err),
+		)
+		return nil, wrapRepositoryError(err)
+	}
+
+	return transferPriceHistoryToServiceDomain(subscription, entities), nil
+}
+
+func (s *SubscriptionService) GetUpcomingRenewals(ctx context.Context, userID uuid.UUID, from time.Time, to time.Time) ([]domain.Renewal, error) {
+	if err := authorizeRead(ctx, userID); err != nil {
+		return []domain.Renewal{}, err
+	}
+
+	entities, err := s.subscriptionRepo.GetActiveSubscriptions(ctx, userID, from, to)
+	if err != nil {
+		s.logger.Error("failed to get active subscriptions in repository",
 			slog.String("user_id", userID.String()),
-			slog.String("start_date", startDate.String()),
-			slog.String("end_date", endDate.String()),
 			slog.Any("error", err),
 		)
-		return []domain.Subscription{}, err
+		return []domain.Renewal{}, wrapRepositoryError(err)
 	}
 
-	domainSubscriptionsList := transferPostgresEntityListsToServiceDomainList(postgresEntities)
+	ids := make([]uuid.UUID, 0, len(entities))
+	for _, entity := range entities {
+		ids = append(ids, entity.SubscriptionID)
+	}
 
-	return domainSubscriptionsList, nil
-}
\ No newline at end of file
+	priceEntities, err := s.subscriptionRepo.GetPricesForSubscriptions(ctx, ids)
+	if err != nil {
+		s.logger.Error("failed to get subscription prices in repository",
+			slog.String("user_id", userID.String()),
+			slog.Any("error", err),
+		)
+		return []domain.Renewal{}, wrapRepositoryError(err)
+	}
+
+	pauseEntities, err := s.subscriptionRepo.GetPausesForSubscriptions(ctx, ids)
+	if err != nil {
+		s.logger.Error("failed to get subscription pauses in repository",
+			slog.String("user_id", userID.String()),
+			slog.Any("error", err),
+		)
+		return []domain.Renewal{}, wrapRepositoryError(err)
+	}
+
+	pauses := make(map[uuid.UUID][]domain.SubscriptionPause)
+	for _, pause := range transferPauseEntitiesToServiceDomain(pauseEntities) {
+		pauses[pause.SubscriptionID] = append(pauses[pause.SubscriptionID], pause)
+	}
+
+	prices := make(map[uuid.UUID][]domain.SubscriptionPrice)
+	for _, entity := range priceEntities {
+		prices[entity.SubscriptionID] = append(prices[entity.SubscriptionID], domain.SubscriptionPrice{
+			SubscriptionID: entity.SubscriptionID,
+			Price:          entity.Price,
+			EffectiveFrom:  entity.EffectiveFrom,
+		})
+	}
+
+	renewals := []domain.Renewal{}
+	for _, entity := range entities {
+		subscription := transferPostgresEntityToServiceDomain(entity)
+		for _, date := range subscription.BillingPeriod.ChargeDatesBetween(subscription.StartDate, subscription.EndDate, from, to) {
+			if slices.ContainsFunc(pauses[subscription.SubscriptionID], func(pause domain.SubscriptionPause) bool { return pause.Covers(date) }) {
+				continue
+			}
+			renewals = append(renewals, domain.Renewal{
+				SubscriptionID: subscription.SubscriptionID,
+				ServiceName:    subscription.ServiceName,
+				ChargeDate:     date,
+				Price:          domain.PriceAt(entity.Price, prices[subscription.SubscriptionID], date),
+				Currency:       subscription.Currency,
+				BillingPeriod:  subscription.BillingPeriod,
+			})
+		}
+	}
+
+	sort.SliceStable(renewals, func(i, j int) bool {
+		return renewals[i].ChargeDate.Before(renewals[j].ChargeDate)
+	})
+
+	s.logger.Debug("upcoming renewals calculated",
+		slog.String("user_id", userID.String()),
+		slog.Int("count", len(renewals)),
+	)
+	return renewals, nil
+}
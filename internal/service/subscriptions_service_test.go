@@ -0,0 +1,538 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kgugunava/effective_mobile_golang/internal/adapters/postgres"
+	"github.com/kgugunava/effective_mobile_golang/internal/domain"
+	"github.com/kgugunava/effective_mobile_golang/internal/requestctx"
+)
+
+// fakeSubscriptionRepository keeps subscriptions in memory. Methods a test
+// does not need panic through the nil embedded interface.
+type fakeSubscriptionRepository struct {
+	SubscriptionRepository
+	subscriptions map[uuid.UUID]postgres.SubscriptionEntity
+	prices        []postgres.SubscriptionPriceEntity
+	pauses        []postgres.SubscriptionPauseEntity
+}
+
+func newFakeSubscriptionRepository(subscriptions ...postgres.SubscriptionEntity) *fakeSubscriptionRepository {
+	r := &fakeSubscriptionRepository{subscriptions: make(map[uuid.UUID]postgres.SubscriptionEntity)}
+	for _, subscription := range subscriptions {
+		r.subscriptions[subscription.SubscriptionID] = subscription
+	}
+	return r
+}
+
+func (r *fakeSubscriptionRepository) GetByID(ctx context.Context, id uuid.UUID) (postgres.SubscriptionEntity, error) {
+	subscription, ok := r.subscriptions[id]
+	if !ok {
+		return postgres.SubscriptionEntity{}, postgres.ErrNotFound
+	}
+	return subscription, nil
+}
+
+func (r *fakeSubscriptionRepository) GetByIDForUpdate(ctx context.Context, id uuid.UUID) (postgres.SubscriptionEntity, error) {
+	return r.GetByID(ctx, id)
+}
+
+func (r *fakeSubscriptionRepository) GetOwner(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	subscription, err := r.GetByID(ctx, id)
+	return subscription.UserID, err
+}
+
+// UpdatePut replaces everything but the price, like the real repository.
+func (r *fakeSubscriptionRepository) UpdatePut(ctx context.Context, sub postgres.SubscriptionEntity, id uuid.UUID, expectedVersions []int) (postgres.SubscriptionEntity, error) {
+	current, err := r.GetByID(ctx, id)
+	if err != nil {
+		return postgres.SubscriptionEntity{}, err
+	}
+	sub.SubscriptionID = id
+	sub.Price = current.Price
+	sub.CurrentPrice = current.CurrentPrice
+	sub.Status = current.Status
+	sub.Version = current.Version + 1
+	r.subscriptions[id] = sub
+	return sub, nil
+}
+
+// UpsertPrice records the price and makes it the current one when it takes
+// effect by the current month, or by the start of a subscription that has
+// not started yet.
+func (r *fakeSubscriptionRepository) UpsertPrice(ctx context.Context, price postgres.SubscriptionPriceEntity) error {
+	r.prices = append(r.prices, price)
+	subscription := r.subscriptions[price.SubscriptionID]
+	now := time.Now()
+	current := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if start := time.Date(subscription.StartDate.Year(), subscription.StartDate.Month(), 1, 0, 0, 0, 0, time.UTC); start.After(current) {
+		current = start
+	}
+	if !price.EffectiveFrom.After(current) {
+		subscription.CurrentPrice = price.Price
+		r.subscriptions[price.SubscriptionID] = subscription
+	}
+	return nil
+}
+
+func (r *fakeSubscriptionRepository) GetPriceHistory(ctx context.Context, id uuid.UUID) ([]postgres.SubscriptionPriceEntity, error) {
+	return r.GetPricesForSubscriptions(ctx, []uuid.UUID{id})
+}
+
+func (r *fakeSubscriptionRepository) BumpVersion(ctx context.Context, id uuid.UUID, expectedVersions []int) (postgres.SubscriptionEntity, error) {
+	return r.bumpVersion(id)
+}
+
+// GetActiveSubscriptions returns every subscription of userID; the charge
+// dates themselves are worked out by the service.
+func (r *fakeSubscriptionRepository) GetActiveSubscriptions(ctx context.Context, userID uuid.UUID, from time.Time, to time.Time) ([]postgres.SubscriptionEntity, error) {
+	var subscriptions []postgres.SubscriptionEntity
+	for _, subscription := range r.subscriptions {
+		if subscription.UserID == userID {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	return subscriptions, nil
+}
+
+func (r *fakeSubscriptionRepository) GetPricesForSubscriptions(ctx context.Context, ids []uuid.UUID) ([]postgres.SubscriptionPriceEntity, error) {
+	var prices []postgres.SubscriptionPriceEntity
+	for _, price := range r.prices {
+		if slices.Contains(ids, price.SubscriptionID) {
+			prices = append(prices, price)
+		}
+	}
+	return prices, nil
+}
+
+func (r *fakeSubscriptionRepository) GetPausesForSubscriptions(ctx context.Context, ids []uuid.UUID) ([]postgres.SubscriptionPauseEntity, error) {
+	var pauses []postgres.SubscriptionPauseEntity
+	for _, pause := range r.pauses {
+		if slices.Contains(ids, pause.SubscriptionID) {
+			pauses = append(pauses, pause)
+		}
+	}
+	return pauses, nil
+}
+
+// Pause records the pause. The status stays as it is, as every pause in
+// these tests starts after the current month.
+func (r *fakeSubscriptionRepository) Pause(ctx context.Context, pause postgres.SubscriptionPauseEntity, expectedVersions []int) (postgres.SubscriptionEntity, error) {
+	r.pauses = append(r.pauses, pause)
+	return r.bumpVersion(pause.SubscriptionID)
+}
+
+// Resume withdraws the pauses starting from resumeMonth on and cuts short
+// the running ones, like the real repository.
+func (r *fakeSubscriptionRepository) Resume(ctx context.Context, id uuid.UUID, resumeMonth time.Time, expectedVersions []int) (postgres.SubscriptionEntity, error) {
+	r.pauses = slices.DeleteFunc(r.pauses, func(pause postgres.SubscriptionPauseEntity) bool {
+		return pause.SubscriptionID == id && !pause.StartMonth.Before(resumeMonth)
+	})
+	for i, pause := range r.pauses {
+		if pause.SubscriptionID == id && !pause.EndMonth.Before(resumeMonth) {
+			r.pauses[i].EndMonth = resumeMonth.AddDate(0, -1, 0)
+		}
+	}
+	subscription := r.subscriptions[id]
+	subscription.Status = string(domain.StatusActive)
+	r.subscriptions[id] = subscription
+	return r.bumpVersion(id)
+}
+
+func (r *fakeSubscriptionRepository) bumpVersion(id uuid.UUID) (postgres.SubscriptionEntity, error) {
+	subscription, ok := r.subscriptions[id]
+	if !ok {
+		return postgres.SubscriptionEntity{}, postgres.ErrNotFound
+	}
+	subscription.Version++
+	r.subscriptions[id] = subscription
+	return subscription, nil
+}
+
+type fakeAuditRepository struct {
+	AuditRepository
+	entries []postgres.AuditEntity
+}
+
+func (r *fakeAuditRepository) Record(ctx context.Context, entries []postgres.AuditEntity) error {
+	r.entries = append(r.entries, entries...)
+	return nil
+}
+
+// List pages through the recorded entries newest first, like the real
+// repository.
+func (r *fakeAuditRepository) List(ctx context.Context, filter postgres.AuditFilter) ([]postgres.AuditEntity, error) {
+	entries := []postgres.AuditEntity{}
+	for i := len(r.entries) - 1; i >= 0 && len(entries) < filter.Limit; i-- {
+		entry := r.entries[i]
+		if filter.UserID != nil && entry.UserID != *filter.UserID {
+			continue
+		}
+		if filter.Before != nil && entry.AuditID >= *filter.Before {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// fakeTxManager runs fn without a transaction.
+type fakeTxManager struct{}
+
+func (fakeTxManager) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func newTestService(repo SubscriptionRepository) *SubscriptionService {
+	return newTestServiceWithAudit(repo, &fakeAuditRepository{})
+}
+
+func newTestServiceWithAudit(repo SubscriptionRepository, auditRepo *fakeAuditRepository) *SubscriptionService {
+	return NewSubscriptionService(repo, auditRepo, fakeTxManager{}, slog.New(slog.DiscardHandler), time.Hour, time.Hour)
+}
+
+func asUser(userID uuid.UUID) context.Context {
+	return requestctx.WithPrincipal(context.Background(), domain.Principal{UserID: userID, Role: domain.RoleUser})
+}
+
+func errorCode(err error) string {
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Code
+	}
+	return ""
+}
+
+func TestUpdateSubscriptionPutPrice(t *testing.T) {
+	now := time.Now()
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	pastMonth := currentMonth.AddDate(-1, 0, 0)
+	futureMonth := currentMonth.AddDate(0, 3, 0)
+
+	tests := []struct {
+		name              string
+		startDate         time.Time
+		endDate           *time.Time
+		price             int
+		wantCode          string
+		wantEffectiveFrom *time.Time
+	}{
+		{name: "unchanged price", startDate: pastMonth, price: 400},
+		{name: "started subscription", startDate: pastMonth, price: 500, wantEffectiveFrom: &currentMonth},
+		{name: "subscription not started yet", startDate: futureMonth, price: 500, wantEffectiveFrom: &futureMonth},
+		{name: "ended subscription", startDate: pastMonth.AddDate(-1, 0, 0), endDate: &pastMonth, price: 500, wantCode: "SUBSCRIPTION_ENDED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID := uuid.New()
+			current := postgres.SubscriptionEntity{
+				SubscriptionID: uuid.New(),
+				ServiceName:    "Netflix",
+				Price:          400,
+				CurrentPrice:   400,
+				UserID:         userID,
+				StartDate:      tt.startDate,
+				EndDate:        tt.endDate,
+				BillingPeriod:  string(domain.BillingPeriodMonthly),
+				Currency:       domain.BaseCurrency,
+				Status:         string(domain.StatusActive),
+				Version:        1,
+			}
+			repo := newFakeSubscriptionRepository(current)
+
+			updated, err := newTestService(repo).UpdateSubscriptionPut(asUser(userID), current.SubscriptionID, &domain.Subscription{
+				ServiceName: "Netflix",
+				Price:       tt.price,
+				StartDate:   tt.startDate,
+				EndDate:     tt.endDate,
+			}, nil)
+
+			if tt.wantCode != "" {
+				if code := errorCode(err); code != tt.wantCode {
+					t.Fatalf("error = %v (code %q), want code %q", err, code, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateSubscriptionPut() error = %v", err)
+			}
+			if updated.Price != tt.price {
+				t.Errorf("price = %d, want %d", updated.Price, tt.price)
+			}
+			if base := repo.subscriptions[current.SubscriptionID].Price; base != 400 {
+				t.Errorf("initial price = %d, want it to stay 400", base)
+			}
+
+			if tt.wantEffectiveFrom == nil {
+				if len(repo.prices) != 0 {
+					t.Errorf("price changes = %+v, want none", repo.prices)
+				}
+				return
+			}
+			if len(repo.prices) != 1 {
+				t.Fatalf("price changes = %+v, want one", repo.prices)
+			}
+			if got := repo.prices[0]; got.Price != tt.price || !got.EffectiveFrom.Equal(*tt.wantEffectiveFrom) {
+				t.Errorf("price change = %+v, want %d from %s", got, tt.price, tt.wantEffectiveFrom.Format(time.DateOnly))
+			}
+		})
+	}
+}
+
+func TestGetUpcomingRenewalsSkipsPausedMonths(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	userID := uuid.New()
+	subscription := postgres.SubscriptionEntity{
+		SubscriptionID: uuid.New(),
+		ServiceName:    "Netflix",
+		Price:          400,
+		CurrentPrice:   400,
+		UserID:         userID,
+		StartDate:      date(2025, time.January, 10),
+		BillingPeriod:  string(domain.BillingPeriodMonthly),
+		Currency:       domain.BaseCurrency,
+		Status:         string(domain.StatusActive),
+		Version:        1,
+	}
+	repo := newFakeSubscriptionRepository(subscription)
+	repo.prices = []postgres.SubscriptionPriceEntity{
+		{SubscriptionID: subscription.SubscriptionID, Price: 500, EffectiveFrom: date(2025, time.May, 1)},
+	}
+	repo.pauses = []postgres.SubscriptionPauseEntity{
+		{SubscriptionID: subscription.SubscriptionID, StartMonth: date(2025, time.March, 1), EndMonth: date(2025, time.April, 1)},
+	}
+
+	renewals, err := newTestService(repo).GetUpcomingRenewals(asUser(userID), userID, date(2025, time.February, 1), date(2025, time.May, 31))
+	if err != nil {
+		t.Fatalf("GetUpcomingRenewals() error = %v", err)
+	}
+
+	want := []struct {
+		chargeDate time.Time
+		price      int
+	}{
+		{chargeDate: date(2025, time.February, 10), price: 400},
+		{chargeDate: date(2025, time.May, 10), price: 500},
+	}
+	if len(renewals) != len(want) {
+		t.Fatalf("renewals = %+v, want %d", renewals, len(want))
+	}
+	for i, renewal := range renewals {
+		if !renewal.ChargeDate.Equal(want[i].chargeDate) || renewal.Price != want[i].price {
+			t.Errorf("renewal %d = %s at %d, want %s at %d", i,
+				renewal.ChargeDate.Format(time.DateOnly), renewal.Price,
+				want[i].chargeDate.Format(time.DateOnly), want[i].price)
+		}
+	}
+}
+
+func TestUpdateSubscriptionPatchCancelledDates(t *testing.T) {
+	userID := uuid.New()
+	endDate := time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)
+	subscription := postgres.SubscriptionEntity{
+		SubscriptionID: uuid.New(),
+		ServiceName:    "Netflix",
+		Price:          400,
+		CurrentPrice:   400,
+		UserID:         userID,
+		StartDate:      time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:        &endDate,
+		BillingPeriod:  string(domain.BillingPeriodMonthly),
+		Currency:       domain.BaseCurrency,
+		Status:         string(domain.StatusCancelled),
+		Version:        1,
+	}
+	repo := newFakeSubscriptionRepository(subscription)
+
+	_, err := newTestService(repo).UpdateSubscriptionPatch(asUser(userID), subscription.SubscriptionID, domain.SubscriptionPatch{ClearEndDate: true}, nil)
+	if code := errorCode(err); code != "ILLEGAL_TRANSITION" {
+		t.Fatalf("error = %v (code %q), want code %q", err, code, "ILLEGAL_TRANSITION")
+	}
+	if got := repo.subscriptions[subscription.SubscriptionID]; got.EndDate == nil || got.Version != 1 {
+		t.Errorf("subscription = %+v, want it unchanged", got)
+	}
+}
+
+func TestScheduledPauses(t *testing.T) {
+	now := time.Now()
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	scheduled := postgres.SubscriptionPauseEntity{
+		StartMonth: currentMonth.AddDate(0, 2, 0),
+		EndMonth:   currentMonth.AddDate(0, 3, 0),
+	}
+
+	newRepo := func(userID uuid.UUID) (*fakeSubscriptionRepository, uuid.UUID) {
+		subscription := postgres.SubscriptionEntity{
+			SubscriptionID: uuid.New(),
+			ServiceName:    "Netflix",
+			Price:          400,
+			CurrentPrice:   400,
+			UserID:         userID,
+			StartDate:      currentMonth.AddDate(-1, 0, 0),
+			BillingPeriod:  string(domain.BillingPeriodMonthly),
+			Currency:       domain.BaseCurrency,
+			Status:         string(domain.StatusActive),
+			Version:        1,
+		}
+		repo := newFakeSubscriptionRepository(subscription)
+		pause := scheduled
+		pause.SubscriptionID = subscription.SubscriptionID
+		repo.pauses = []postgres.SubscriptionPauseEntity{pause}
+		return repo, subscription.SubscriptionID
+	}
+
+	t.Run("overlapping pause is rejected", func(t *testing.T) {
+		userID := uuid.New()
+		repo, id := newRepo(userID)
+
+		_, err := newTestService(repo).PauseSubscription(asUser(userID), id, scheduled.StartMonth, scheduled.StartMonth, nil)
+		if code := errorCode(err); code != "VALIDATION_FAILED" {
+			t.Fatalf("error = %v (code %q), want code %q", err, code, "VALIDATION_FAILED")
+		}
+		if len(repo.pauses) != 1 {
+			t.Errorf("pauses = %+v, want only the scheduled one", repo.pauses)
+		}
+	})
+
+	t.Run("non-overlapping pause is added", func(t *testing.T) {
+		userID := uuid.New()
+		repo, id := newRepo(userID)
+
+		if _, err := newTestService(repo).PauseSubscription(asUser(userID), id, currentMonth.AddDate(0, 5, 0), currentMonth.AddDate(0, 6, 0), nil); err != nil {
+			t.Fatalf("PauseSubscription() error = %v", err)
+		}
+		if len(repo.pauses) != 2 {
+			t.Errorf("pauses = %+v, want two", repo.pauses)
+		}
+	})
+
+	t.Run("resume withdraws a scheduled pause", func(t *testing.T) {
+		userID := uuid.New()
+		repo, id := newRepo(userID)
+
+		resumed, err := newTestService(repo).ResumeSubscription(asUser(userID), id, nil)
+		if err != nil {
+			t.Fatalf("ResumeSubscription() error = %v", err)
+		}
+		if resumed.Status != domain.StatusActive {
+			t.Errorf("status = %q, want %q", resumed.Status, domain.StatusActive)
+		}
+		if len(repo.pauses) != 0 {
+			t.Errorf("pauses = %+v, want the scheduled pause withdrawn", repo.pauses)
+		}
+	})
+
+	t.Run("resume without a pause is illegal", func(t *testing.T) {
+		userID := uuid.New()
+		repo, id := newRepo(userID)
+		repo.pauses = nil
+
+		_, err := newTestService(repo).ResumeSubscription(asUser(userID), id, nil)
+		if code := errorCode(err); code != "ILLEGAL_TRANSITION" {
+			t.Fatalf("error = %v (code %q), want code %q", err, code, "ILLEGAL_TRANSITION")
+		}
+	})
+}
+
+func TestSchedulePriceChange(t *testing.T) {
+	now := time.Now()
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		effectiveFrom    time.Time
+		expectedVersions []int
+		wantCode         string
+		wantVersion      int
+		wantPrice        int
+	}{
+		{name: "from the current month", effectiveFrom: currentMonth, wantVersion: 2, wantPrice: 500},
+		{name: "from a later month", effectiveFrom: currentMonth.AddDate(0, 2, 0), wantVersion: 1, wantPrice: 400},
+		{name: "matching If-Match", effectiveFrom: currentMonth, expectedVersions: []int{1}, wantVersion: 2, wantPrice: 500},
+		{name: "stale If-Match", effectiveFrom: currentMonth, expectedVersions: []int{7}, wantCode: "VERSION_MISMATCH"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID := uuid.New()
+			current := postgres.SubscriptionEntity{
+				SubscriptionID: uuid.New(),
+				ServiceName:    "Netflix",
+				Price:          400,
+				CurrentPrice:   400,
+				UserID:         userID,
+				StartDate:      currentMonth.AddDate(-1, 0, 0),
+				BillingPeriod:  string(domain.BillingPeriodMonthly),
+				Currency:       domain.BaseCurrency,
+				Status:         string(domain.StatusActive),
+				Version:        1,
+			}
+			repo := newFakeSubscriptionRepository(current)
+			auditRepo := &fakeAuditRepository{}
+
+			subscription, _, err := newTestServiceWithAudit(repo, auditRepo).SchedulePriceChange(asUser(userID), domain.SubscriptionPrice{
+				SubscriptionID: current.SubscriptionID,
+				Price:          500,
+				EffectiveFrom:  tt.effectiveFrom,
+			}, tt.expectedVersions)
+
+			if tt.wantCode != "" {
+				if code := errorCode(err); code != tt.wantCode {
+					t.Fatalf("error = %v (code %q), want code %q", err, code, tt.wantCode)
+				}
+				if len(repo.prices) != 0 || len(auditRepo.entries) != 0 {
+					t.Errorf("prices = %+v, audit = %+v, want neither recorded", repo.prices, auditRepo.entries)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SchedulePriceChange() error = %v", err)
+			}
+			if subscription.Version != tt.wantVersion || subscription.Price != tt.wantPrice {
+				t.Errorf("subscription = version %d at %d, want version %d at %d", subscription.Version, subscription.Price, tt.wantVersion, tt.wantPrice)
+			}
+			if len(auditRepo.entries) != 1 || auditRepo.entries[0].Action != string(domain.AuditActionPriceChange) {
+				t.Errorf("audit = %+v, want one price change", auditRepo.entries)
+			}
+		})
+	}
+}
+
+func TestGetAuditHistoryPages(t *testing.T) {
+	userID := uuid.New()
+	auditRepo := &fakeAuditRepository{}
+	for id := int64(1); id <= 5; id++ {
+		auditRepo.entries = append(auditRepo.entries, postgres.AuditEntity{AuditID: id, SubscriptionID: uuid.New(), UserID: userID, Action: string(domain.AuditActionCreate)})
+	}
+	service := newTestServiceWithAudit(newFakeSubscriptionRepository(), auditRepo)
+
+	var got []int64
+	query := domain.AuditQuery{UserID: &userID, Limit: 2}
+	for {
+		page, err := service.GetAuditHistory(asUser(userID), query)
+		if err != nil {
+			t.Fatalf("GetAuditHistory() error = %v", err)
+		}
+		for _, entry := range page.Entries {
+			got = append(got, entry.AuditID)
+		}
+		if page.NextBefore == nil {
+			break
+		}
+		query.Before = page.NextBefore
+	}
+
+	if want := []int64{5, 4, 3, 2, 1}; !slices.Equal(got, want) {
+		t.Errorf("audit IDs = %v, want %v", got, want)
+	}
+}
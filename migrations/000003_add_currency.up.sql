@@ -0,0 +1,11 @@
+ALTER TABLE subscriptions
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'RUB';
+
+CREATE TABLE exchange_rates (
+    currency CHAR(3) NOT NULL,
+    rate NUMERIC(20, 8) NOT NULL CHECK (rate > 0),
+    effective_from DATE NOT NULL,
+    PRIMARY KEY (currency, effective_from)
+);
+
+INSERT INTO exchange_rates (currency, rate, effective_from) VALUES ('RUB', 1, '1970-01-01');
\ No newline at end of file
@@ -0,0 +1,6 @@
+CREATE TABLE subscription_prices (
+    subscription_id UUID NOT NULL REFERENCES subscriptions (subscription_id) ON DELETE CASCADE,
+    price INTEGER NOT NULL CHECK (price > 0),
+    effective_from DATE NOT NULL,
+    PRIMARY KEY (subscription_id, effective_from)
+);
\ No newline at end of file
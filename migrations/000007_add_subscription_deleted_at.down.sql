@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS subscriptions_deleted_at_idx;
+
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS deleted_at;
\ No newline at end of file
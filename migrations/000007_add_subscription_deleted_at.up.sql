@@ -0,0 +1,4 @@
+ALTER TABLE subscriptions
+    ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX subscriptions_deleted_at_idx ON subscriptions (deleted_at) WHERE deleted_at IS NOT NULL;
\ No newline at end of file
@@ -0,0 +1,14 @@
+CREATE TABLE subscription_audit (
+    audit_id BIGSERIAL PRIMARY KEY,
+    subscription_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    action TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    request_id TEXT NOT NULL,
+    before JSONB,
+    after JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX subscription_audit_subscription_idx ON subscription_audit (subscription_id, audit_id);
+CREATE INDEX subscription_audit_user_idx ON subscription_audit (user_id, audit_id);
\ No newline at end of file
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS subscription_pauses;
+
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS status;
\ No newline at end of file
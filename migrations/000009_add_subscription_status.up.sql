@@ -0,0 +1,11 @@
+ALTER TABLE subscriptions
+    ADD COLUMN status TEXT NOT NULL DEFAULT 'active'
+        CHECK (status IN ('active', 'paused', 'cancelled'));
+
+CREATE TABLE subscription_pauses (
+    subscription_id UUID NOT NULL REFERENCES subscriptions (subscription_id) ON DELETE CASCADE,
+    start_month DATE NOT NULL,
+    end_month DATE NOT NULL,
+    PRIMARY KEY (subscription_id, start_month),
+    CHECK (end_month >= start_month)
+);
\ No newline at end of file
@@ -0,0 +1,9 @@
+CREATE TABLE api_keys (
+    key_id UUID PRIMARY KEY,
+    name TEXT NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL CHECK (cardinality(scopes) > 0),
+    expires_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    revoked_at TIMESTAMPTZ
+);
\ No newline at end of file